@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/HARSH16DAWAR/falcon-cli/cmd/filter"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// alertsQueryEndpoint is the query endpoint for alert composite IDs
+const alertsQueryEndpoint = "/alerts/queries/alerts/v2"
+
+// getAlertsFilterValue returns the alerts filter selected by --filter, --filter-name and --param
+func getAlertsFilterValue(cmd *cobra.Command) (string, error) {
+	return filter.FromFlags(cmd, "alerts")
+}
+
+// alertIDColumns are the columns shown when listing alert IDs
+var alertIDColumns = []output.Column{
+	{Header: "COMPOSITE ID"},
+}
+
+// alertsCmd represents the alerts command
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "List alerts in your Falcon environment",
+	Long: `List the composite IDs of alerts in your Falcon environment. You can filter alerts using the --filter flag
+or saved filters of type alerts using --filter-name, and order them with --sort.
+
+Results are paginated with --limit and --offset. Use --all to walk every page, or --max-results to stop after a given number of alerts.
+Use 'alerts get' to show alert details and 'alerts update' to triage them.`,
+	Example: `  falcon-cli alerts --filter "status:'new'+severity_name:'Critical'" --sort created_timestamp.desc
+  falcon-cli alerts --filter-name open-high --count-only`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get filter value
+		filterValue, err := getAlertsFilterValue(cmd)
+		if err != nil {
+			return err
+		}
+		if explainFilter(cmd, filterValue) {
+			return nil
+		}
+
+		// Get pagination options
+		pageOpts, err := getPageOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		params := getQueryParams(cmd, filterValue)
+
+		// Only read the total when just the count is wanted
+		if countOnly, _ := cmd.Flags().GetBool("count-only"); countOnly {
+			total, meta, err := client.Count(alertsQueryEndpoint, params)
+			if err != nil {
+				return fmt.Errorf("error counting alerts: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), total)
+			fmt.Fprintf(os.Stderr, "Query time: %.3fs\n", meta.QueryTime)
+			return nil
+		}
+
+		printer, err := output.NewFromFlags(cmd, alertIDColumns)
+		if err != nil {
+			return err
+		}
+
+		// Stream alert IDs as each page arrives
+		count, meta, err := client.QueryIDs(alertsQueryEndpoint, params, pageOpts, func(ids []string, _ utils.QueryMeta) error {
+			for _, id := range ids {
+				if err := printer.Add(id); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error getting alerts: %w", err)
+		}
+		if err := printer.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Found %d of %d alerts\n", count, meta.Pagination.Total)
+
+		return nil
+	},
+}
+
+func init() {
+	addFilterFlags(alertsCmd, "alerts")
+	addSortFlag(alertsCmd, "created_timestamp.desc")
+	alertsCmd.Flags().Bool("count-only", false, "Print the number of matching alerts instead of their IDs")
+	addPageFlags(alertsCmd)
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/HARSH16DAWAR/falcon-cli/cmd/filter"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// maxAlertIDsPerRequest is the maximum number of composite IDs accepted by the alert entities endpoints
+const maxAlertIDsPerRequest = 1000
+
+// AlertDevice is the host an alert was raised on
+type AlertDevice struct {
+	DeviceID     string `json:"device_id"`
+	Hostname     string `json:"hostname"`
+	PlatformName string `json:"platform_name"`
+	LocalIP      string `json:"local_ip"`
+	ExternalIP   string `json:"external_ip"`
+}
+
+// Alert represents an alert returned by the alert entities API
+type Alert struct {
+	CompositeID      string      `json:"composite_id"`
+	ID               string      `json:"id"`
+	Name             string      `json:"name"`
+	DisplayName      string      `json:"display_name"`
+	Description      string      `json:"description"`
+	Status           string      `json:"status"`
+	Severity         int         `json:"severity"`
+	SeverityName     string      `json:"severity_name"`
+	Confidence       int         `json:"confidence"`
+	Product          string      `json:"product"`
+	Type             string      `json:"type"`
+	Tactic           string      `json:"tactic"`
+	Technique        string      `json:"technique"`
+	Filename         string      `json:"filename"`
+	Cmdline          string      `json:"cmdline"`
+	UserName         string      `json:"user_name"`
+	Device           AlertDevice `json:"device"`
+	AssignedToName   string      `json:"assigned_to_name"`
+	AssignedToUUID   string      `json:"assigned_to_uuid"`
+	Tags             []string    `json:"tags"`
+	CreatedTimestamp string      `json:"created_timestamp"`
+	UpdatedTimestamp string      `json:"updated_timestamp"`
+	FalconHostLink   string      `json:"falcon_host_link"`
+}
+
+// AlertsResponse represents the response from the alert entities API
+type AlertsResponse struct {
+	Resources []Alert `json:"resources"`
+	Errors    []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Meta utils.QueryMeta `json:"meta"`
+}
+
+// getAlerts resolves composite IDs to full alert details, batching requests to the API's limit
+func getAlerts(client *utils.FalconClient, ids []string) ([]Alert, error) {
+	var alerts []Alert
+	for _, chunk := range utils.ChunkIDs(ids, maxAlertIDsPerRequest) {
+		payload, err := json.Marshal(map[string][]string{"composite_ids": chunk})
+		if err != nil {
+			return nil, fmt.Errorf("error encoding request: %v", err)
+		}
+
+		resp, err := client.PostWithRetry("/alerts/entities/alerts/v2", bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("error getting alert details: %w", err)
+		}
+
+		var result AlertsResponse
+		if err := client.ParseResponse(resp, &result); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, result.Resources...)
+	}
+	return alerts, nil
+}
+
+// alertColumns are the default columns shown for alert details
+var alertColumns = []output.Column{
+	{Header: "CREATED", Field: "created_timestamp"},
+	{Header: "SEVERITY", Field: "severity_name"},
+	{Header: "STATUS", Field: "status"},
+	{Header: "NAME", Field: "display_name"},
+	{Header: "HOSTNAME", Field: "device.hostname"},
+	{Header: "ASSIGNED TO", Field: "assigned_to_name"},
+	{Header: "TAGS", Field: "tags"},
+	{Header: "COMPOSITE ID", Field: "composite_id"},
+}
+
+// alertsGetCmd represents the alerts get command
+var alertsGetCmd = &cobra.Command{
+	Use:   "get [COMPOSITE_ID...]",
+	Short: "Show full details for alerts",
+	Long: `Show creation time, severity, status, name, hostname, assignee and tags for alerts. Use -o json or
+-o yaml to see every field of the alert.
+
+Composite IDs can be given as arguments. Without arguments, alerts are selected with --filter or --filter-name,
+ordered with --sort and paginated the same way as the alerts command.`,
+	Example: `  falcon-cli alerts get --filter "status:'new'" --sort severity.desc --limit 20
+  falcon-cli alerts get -o json 1a2b3c:ind:4d5e6f:7890`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pageOpts, err := getPageOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Get filter value
+		filterValue, err := getAlertsFilterValue(cmd)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 && filterValue != "" {
+			return fmt.Errorf("cannot use composite IDs together with --filter or --filter-name")
+		}
+		if explainFilter(cmd, filterValue) {
+			return nil
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		printer, err := output.NewFromFlags(cmd, alertColumns)
+		if err != nil {
+			return err
+		}
+
+		// printAlerts resolves a batch of IDs and hands the alerts to the printer
+		printAlerts := func(ids []string) error {
+			alerts, err := getAlerts(client, ids)
+			if err != nil {
+				return err
+			}
+			for _, a := range alerts {
+				if err := printer.Add(a); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if len(args) > 0 {
+			if err := printAlerts(args); err != nil {
+				return err
+			}
+		} else {
+			// Resolve each page of IDs as it arrives
+			_, _, err = client.QueryIDs(alertsQueryEndpoint, getQueryParams(cmd, filterValue), pageOpts, func(ids []string, _ utils.QueryMeta) error {
+				return printAlerts(ids)
+			})
+			if err != nil {
+				return fmt.Errorf("error getting alerts: %w", err)
+			}
+		}
+
+		return printer.Flush()
+	},
+}
+
+func init() {
+	addFilterFlags(alertsGetCmd, "alerts")
+	addSortFlag(alertsGetCmd, "created_timestamp.desc")
+	addPageFlags(alertsGetCmd)
+	alertsCmd.AddCommand(alertsGetCmd)
+
+	// Show alert names and hostnames as filter test samples
+	filter.RegisterSampler("alerts", func(client *utils.FalconClient, ids []string) ([]string, error) {
+		alerts, err := getAlerts(client, ids)
+		if err != nil {
+			return nil, err
+		}
+		labels := make([]string, len(alerts))
+		for i, a := range alerts {
+			labels[i] = fmt.Sprintf("%s on %s (%s)", a.DisplayName, a.Device.Hostname, a.CompositeID)
+		}
+		return labels, nil
+	})
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/fql"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// ActionParameter is a single change in an alert or incident update request
+type ActionParameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// alertActions returns the changes requested by the alerts update flags
+func alertActions(cmd *cobra.Command) ([]ActionParameter, error) {
+	status, _ := cmd.Flags().GetString("status")
+	assign, _ := cmd.Flags().GetString("assign")
+	unassign, _ := cmd.Flags().GetBool("unassign")
+	addTags, _ := cmd.Flags().GetStringArray("add-tag")
+	removeTags, _ := cmd.Flags().GetStringArray("remove-tag")
+	comment, _ := cmd.Flags().GetString("comment")
+
+	var actions []ActionParameter
+	if status != "" {
+		if valid := fql.Values("alerts", "status"); !slices.Contains(valid, status) {
+			return nil, fmt.Errorf("invalid --status '%s' (valid statuses: %s)", status, strings.Join(valid, ", "))
+		}
+		actions = append(actions, ActionParameter{Name: "update_status", Value: status})
+	}
+	if assign != "" && unassign {
+		return nil, fmt.Errorf("cannot use --assign together with --unassign")
+	}
+	if assign != "" {
+		actions = append(actions, ActionParameter{Name: "assign_to_name", Value: assign})
+	}
+	if unassign {
+		actions = append(actions, ActionParameter{Name: "unassign", Value: ""})
+	}
+	for _, tag := range addTags {
+		actions = append(actions, ActionParameter{Name: "add_tag", Value: tag})
+	}
+	for _, tag := range removeTags {
+		actions = append(actions, ActionParameter{Name: "remove_tag", Value: tag})
+	}
+	if comment != "" {
+		actions = append(actions, ActionParameter{Name: "append_comment", Value: comment})
+	}
+
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("nothing to update; use --status, --assign, --unassign, --add-tag, --remove-tag or --comment")
+	}
+	return actions, nil
+}
+
+// confirmBulkUpdate checks the number of alerts or incidents a filter matches
+// against --max-<kind>, then lists the changes and asks whether to go ahead,
+// unless --yes is set. It fails when there is no terminal to ask on.
+func confirmBulkUpdate(cmd *cobra.Command, kind string, count int, actions []ActionParameter) (bool, error) {
+	maxCount, _ := cmd.Flags().GetInt("max-" + kind)
+	if maxCount <= 0 {
+		return false, fmt.Errorf("--max-%s must be positive", kind)
+	}
+	if count > maxCount {
+		return false, fmt.Errorf("%d %s match the filter; refusing to update more than %d, raise --max-%s to update them all", count, kind, maxCount, kind)
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("cannot ask for confirmation without an interactive terminal; use --yes to update the %d %s", count, kind)
+	}
+
+	fmt.Printf("About to update %d %s matching the filter:\n", count, kind)
+	for _, action := range actions {
+		fmt.Printf("  %s %s\n", action.Name, action.Value)
+	}
+
+	proceed := false
+	prompt := &survey.Confirm{Message: fmt.Sprintf("Update these %d %s?", count, kind)}
+	if err := survey.AskOne(prompt, &proceed); err != nil {
+		return false, fmt.Errorf("failed to get answer: %v", err)
+	}
+	return proceed, nil
+}
+
+// selectForUpdate returns the IDs of the alerts or incidents matching a filter,
+// after counting them and confirming the update with confirmBulkUpdate. It
+// returns no IDs when nothing matches or the update is not confirmed.
+func selectForUpdate(cmd *cobra.Command, client *utils.FalconClient, kind, endpoint, filterValue string, actions []ActionParameter) ([]string, error) {
+	count, _, err := client.Count(endpoint, getQueryParams(cmd, filterValue))
+	if err != nil {
+		return nil, fmt.Errorf("error counting %s: %w", kind, err)
+	}
+	if count == 0 {
+		fmt.Printf("No %s match the filter\n", kind)
+		return nil, nil
+	}
+
+	proceed, err := confirmBulkUpdate(cmd, kind, count, actions)
+	if err != nil {
+		return nil, err
+	}
+	if !proceed {
+		fmt.Println("Aborted")
+		return nil, nil
+	}
+
+	// Update no more than the confirmed number, even if more match by now
+	var ids []string
+	_, _, err = client.QueryIDs(endpoint, getQueryParams(cmd, filterValue), utils.PageOptions{All: true, MaxResults: count}, func(page []string, _ utils.QueryMeta) error {
+		ids = append(ids, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s: %w", kind, err)
+	}
+	return ids, nil
+}
+
+// addBulkUpdateFlags adds the safeguard flags of updates that select by filter
+func addBulkUpdateFlags(cmd *cobra.Command, kind string, defaultMax int) {
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().Int("max-"+kind, defaultMax, fmt.Sprintf("Refuse to update more than this many %s matching the filter", kind))
+}
+
+// updateAlerts applies actions to alerts, batching requests to the API's limit.
+// It returns the number of alerts updated before any error.
+func updateAlerts(client *utils.FalconClient, ids []string, actions []ActionParameter) (int, error) {
+	updated := 0
+	for _, chunk := range utils.ChunkIDs(ids, maxAlertIDsPerRequest) {
+		payload, err := json.Marshal(map[string]interface{}{
+			"composite_ids":     chunk,
+			"action_parameters": actions,
+		})
+		if err != nil {
+			return updated, fmt.Errorf("error encoding request: %v", err)
+		}
+
+		resp, err := client.Patch("/alerts/entities/alerts/v3", bytes.NewReader(payload))
+		if err != nil {
+			return updated, fmt.Errorf("error updating alerts: %w", err)
+		}
+		resp.Body.Close()
+		updated += len(chunk)
+	}
+	return updated, nil
+}
+
+// alertsUpdateCmd represents the alerts update command
+var alertsUpdateCmd = &cobra.Command{
+	Use:   "update [COMPOSITE_ID...]",
+	Short: "Change the status, assignee, tags or comments of alerts",
+	Long: `Change alerts with the alerts combined update endpoint. Several changes can be made at once, for example
+closing alerts and adding a comment explaining why.
+
+Composite IDs can be given as arguments. Without arguments, the alerts matching --filter or --filter-name are
+updated: they are counted, the changes are shown and confirmation is asked for; use --yes to skip the question
+in scripts. More than --max-alerts alerts are never updated at once. Use --explain to check the filter.`,
+	Example: `  falcon-cli alerts update 1a2b3c:ind:4d5e6f:7890 --status in_progress --assign "Jane Analyst"
+  falcon-cli alerts update --filter-name scanner-noise --status closed --add-tag false_positive \
+    --comment "Authorised vulnerability scan" --max-alerts 500`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		actions, err := alertActions(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Get filter value
+		filterValue, err := getAlertsFilterValue(cmd)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 && filterValue != "" {
+			return fmt.Errorf("cannot use composite IDs together with --filter or --filter-name")
+		}
+		if len(args) == 0 && filterValue == "" {
+			return fmt.Errorf("give composite IDs, --filter or --filter-name to select the alerts to update")
+		}
+		if explainFilter(cmd, filterValue) {
+			return nil
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		ids := args
+		if len(ids) == 0 {
+			ids, err = selectForUpdate(cmd, client, "alerts", alertsQueryEndpoint, filterValue, actions)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+		}
+
+		updated, err := updateAlerts(client, ids, actions)
+		if err != nil {
+			if updated > 0 {
+				fmt.Printf("Updated %d of %d alerts before the error\n", updated, len(ids))
+			}
+			return err
+		}
+
+		fmt.Printf("Updated %d alerts\n", updated)
+		return nil
+	},
+}
+
+func init() {
+	addFilterFlags(alertsUpdateCmd, "alerts")
+	addBulkUpdateFlags(alertsUpdateCmd, "alerts", 100)
+	alertsUpdateCmd.Flags().String("status", "", "Set the status: new, in_progress, closed or reopened")
+	alertsUpdateCmd.Flags().String("assign", "", "Assign the alerts to this user name")
+	alertsUpdateCmd.Flags().Bool("unassign", false, "Remove the assignee")
+	alertsUpdateCmd.Flags().StringArray("add-tag", nil, "Add a tag (repeatable)")
+	alertsUpdateCmd.Flags().StringArray("remove-tag", nil, "Remove a tag (repeatable)")
+	alertsUpdateCmd.Flags().String("comment", "", "Add a comment")
+	alertsUpdateCmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(fql.Values("alerts", "status"), cobra.ShellCompDirectiveNoFileComp))
+	alertsCmd.AddCommand(alertsUpdateCmd)
+}
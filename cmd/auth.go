@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// TokenStatus describes the cached token for the configured credentials
+type TokenStatus struct {
+	Profile     string `json:"profile"`
+	ClientID    string `json:"client_id"`
+	CloudRegion string `json:"cloud_region"`
+	Status      string `json:"status"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	ExpiresIn   string `json:"expires_in,omitempty"`
+}
+
+// tokenStatusColumns are the columns shown by auth status
+var tokenStatusColumns = []output.Column{
+	{Header: "PROFILE", Field: "profile"},
+	{Header: "CLIENT ID", Field: "client_id"},
+	{Header: "REGION", Field: "cloud_region"},
+	{Header: "STATUS", Field: "status"},
+	{Header: "EXPIRES AT", Field: "expires_at"},
+	{Header: "EXPIRES IN", Field: "expires_in"},
+}
+
+// authCmd represents the auth command
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the cached OAuth2 token",
+	Long: `Inspect, refresh and revoke the OAuth2 token used to call the Falcon API.
+
+Tokens are cached under ~/.falcon-cli/tokens/ and reused across invocations until shortly
+before they expire, so repeated runs do not each request a new token.`,
+}
+
+// authStatusCmd represents the auth status command
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the cached token status",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := utils.ActiveProfile()
+		if err != nil {
+			return err
+		}
+
+		status := TokenStatus{
+			Profile:     profile.Name,
+			ClientID:    profile.ClientID,
+			CloudRegion: profile.CloudRegion,
+			Status:      "none",
+		}
+
+		if expiresAt := utils.GetTokenManager().ExpiresAt(); !expiresAt.IsZero() {
+			status.Status = "valid"
+			status.ExpiresAt = expiresAt.Format(time.RFC3339)
+			status.ExpiresIn = time.Until(expiresAt).Round(time.Second).String()
+		}
+
+		printer, err := output.NewFromFlags(cmd, tokenStatusColumns)
+		if err != nil {
+			return err
+		}
+		if err := printer.Add(status); err != nil {
+			return err
+		}
+		return printer.Flush()
+	},
+}
+
+// authRefreshCmd represents the auth refresh command
+var authRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Request a new token and cache it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tm := utils.GetTokenManager()
+		if _, err := tm.Refresh(); err != nil {
+			return fmt.Errorf("error refreshing token: %w", err)
+		}
+
+		fmt.Printf("Token refreshed, expires at %s\n", tm.ExpiresAt().Format(time.RFC3339))
+		return nil
+	},
+}
+
+// authRevokeCmd represents the auth revoke command
+var authRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke the cached token and remove it from disk",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tm := utils.GetTokenManager()
+		if tm.ExpiresAt().IsZero() {
+			fmt.Println("No cached token to revoke")
+			return nil
+		}
+
+		if err := tm.Revoke(); err != nil {
+			return err
+		}
+
+		fmt.Println("Token revoked")
+		return nil
+	},
+}
+
+func init() {
+	authCmd.AddCommand(authStatusCmd)
+	authCmd.AddCommand(authRefreshCmd)
+	authCmd.AddCommand(authRevokeCmd)
+}
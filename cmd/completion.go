@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate a shell completion script",
+	Long: `Generate a completion script for your shell. Besides commands and flags, it completes
+saved filter names for --filter-name and filter commands, filter types for --type, and
+profile names for --profile.
+
+Bash (requires the bash-completion package):
+  source <(falcon-cli completion bash)
+  # or, to load it in every session:
+  falcon-cli completion bash > /etc/bash_completion.d/falcon-cli
+
+Zsh:
+  # enable completion once, if it is not already enabled
+  echo "autoload -U compinit; compinit" >> ~/.zshrc
+  falcon-cli completion zsh > "${fpath[1]}/_falcon-cli"
+
+Fish:
+  falcon-cli completion fish > ~/.config/fish/completions/falcon-cli.fish
+
+PowerShell:
+  falcon-cli completion powershell | Out-String | Invoke-Expression
+  # or add the output to your PowerShell profile`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		switch args[0] {
+		case "bash":
+			return cmd.Root().GenBashCompletionV2(out, true)
+		case "zsh":
+			return cmd.Root().GenZshCompletion(out)
+		case "fish":
+			return cmd.Root().GenFishCompletion(out, true)
+		case "powershell":
+			return cmd.Root().GenPowerShellCompletionWithDesc(out)
+		}
+		return fmt.Errorf("unsupported shell '%s'", args[0])
+	},
+}
@@ -4,105 +4,298 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package config
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 )
 
+// cloudRegions are the regions offered by init
+var cloudRegions = []string{"us-1", "us-2", "eu-1", "us-gov-1", "us-gov-2"}
+
+// scopeColumns are the columns shown by init --check-scopes
+var scopeColumns = []output.Column{
+	{Header: "SCOPE", Field: "scope"},
+	{Header: "GRANTED", Field: "granted"},
+	{Header: "DETAIL", Field: "detail"},
+}
+
 // initCmd represents the init command
 var InitCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize Falcon CLI configuration",
 	Long: `This command will guide you through setting up your Falcon CLI configuration.
-It will prompt you for your Falcon API credentials and cloud region.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		// Questions for the user
-		qs := []*survey.Question{
-			{
-				Name: "client_id",
-				Prompt: &survey.Input{
-					Message: "Enter your Falcon Client ID:",
-				},
-				Validate: func(val interface{}) error {
-					if str, ok := val.(string); !ok || len(str) == 0 {
-						return fmt.Errorf("Client ID cannot be empty")
-					}
-					return nil
-				},
-			},
-			{
-				Name: "client_secret",
-				Prompt: &survey.Password{
-					Message: "Enter your Falcon Client Secret:",
-				},
-				Validate: func(val interface{}) error {
-					if str, ok := val.(string); !ok || len(str) == 0 {
-						return fmt.Errorf("Client Secret cannot be empty")
-					}
-					return nil
-				},
-			},
-			{
-				Name: "cloud_region",
-				Prompt: &survey.Select{
-					Message: "Select your Falcon Cloud Region:",
-					Options: []string{"us-1", "us-2", "eu-1", "us-gov-1", "us-gov-2"},
-					Default: "us-1",
-				},
-			},
+It will prompt you for your Falcon API credentials and cloud region.
+
+Use --profile NAME to add or update a named profile instead of the default one.
+
+The client secret is stored in the OS keyring when one is available, and otherwise in a
+passphrase-encrypted file (~/.falcon-cli/secrets.enc). Use --secret-backend to choose.
+
+For CI and provisioning, credentials can be given without prompts using --client-id,
+--client-secret-stdin and --region, or the FALCON_CLIENT_ID, FALCON_CLIENT_SECRET and
+FALCON_CLOUD_REGION environment variables. Before anything is written, the credentials
+are checked by requesting a token.`,
+	Example: `  # Interactive setup
+  falcon-cli init
+
+  # Non-interactive setup of a named profile
+  echo "$SECRET" | falcon-cli init --profile staging --client-id "$CLIENT_ID" --client-secret-stdin --region eu-1
+
+  # Environment variables only
+  FALCON_CLIENT_ID=... FALCON_CLIENT_SECRET=... FALCON_CLOUD_REGION=us-2 falcon-cli init --secret-backend file`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		checkScopes, _ := cmd.Flags().GetBool("check-scopes")
+
+		// Work out which profile to write
+		profileName := utils.ActiveProfileName()
+		if profileName != utils.DefaultProfile {
+			if err := utils.ValidateProfileName(profileName); err != nil {
+				return err
+			}
+		}
+		key := utils.ProfileKey(profileName)
+
+		// Work out where to keep the client secret
+		backend, _ := cmd.Flags().GetString("secret-backend")
+		if backend == "" {
+			backend = utils.SecretBackendFile
+			if utils.KeyringAvailable() {
+				backend = utils.SecretBackendKeyring
+			}
+		}
+
+		// Gather credentials from flags and environment variables
+		answers, err := credentialsFromFlags(cmd)
+		if err != nil {
+			return err
 		}
 
-		// Answers will be stored here
-		answers := struct {
-			ClientID     string `survey:"client_id"`
-			ClientSecret string `survey:"client_secret"`
-			CloudRegion  string `survey:"cloud_region"`
-		}{}
+		interactive := term.IsTerminal(int(os.Stdin.Fd()))
 
-		// Perform the questions
-		err := survey.Ask(qs, &answers)
+		// Don't silently replace an existing profile
+		if viper.GetString(key+".client_id") != "" && !force {
+			if !interactive {
+				return fmt.Errorf("profile '%s' already exists, use --force to overwrite it", profileName)
+			}
+			overwrite := false
+			prompt := &survey.Confirm{Message: fmt.Sprintf("Profile '%s' already exists. Overwrite it?", profileName)}
+			if err := survey.AskOne(prompt, &overwrite); err != nil {
+				return fmt.Errorf("failed to get answer: %v", err)
+			}
+			if !overwrite {
+				return nil
+			}
+		}
+
+		// Prompt for anything that was not given
+		if answers.ClientID == "" || answers.ClientSecret == "" || answers.CloudRegion == "" {
+			if !interactive {
+				return fmt.Errorf("missing credentials: provide --client-id, --client-secret-stdin and --region, or set FALCON_CLIENT_ID, FALCON_CLIENT_SECRET and FALCON_CLOUD_REGION")
+			}
+			if err := askCredentials(&answers); err != nil {
+				return err
+			}
+		}
+
+		if _, ok := utils.RegionBaseURL[answers.CloudRegion]; !ok {
+			return fmt.Errorf("invalid cloud region '%s' (valid regions: %s)", answers.CloudRegion, strings.Join(cloudRegions, ", "))
+		}
+
+		// Check the credentials before writing anything
+		token, err := utils.ValidateCredentials(answers.ClientID, answers.ClientSecret, answers.CloudRegion)
 		if err != nil {
-			fmt.Printf("Failed to get answers: %v\n", err)
-			return
+			return fmt.Errorf("could not validate credentials, nothing was saved: %w", err)
+		}
+		if checkScopes {
+			if err := printScopes(cmd, answers, token); err != nil {
+				return fmt.Errorf("could not check scopes, nothing was saved: %w", err)
+			}
 		}
 
-		// Get home directory
-		home, err := os.UserHomeDir()
+		// Save to the file given with --config, or ~/.falcon-cli/config.yaml
+		configPath, err := utils.ConfigPath()
 		if err != nil {
-			fmt.Printf("Failed to get home directory: %v\n", err)
-			return
+			return err
 		}
 
-		// Create .falcon-cli directory if it doesn't exist
-		falconDir := filepath.Join(home, ".falcon-cli")
-		err = os.MkdirAll(falconDir, 0755)
+		// Create the config directory if it doesn't exist
+		err = os.MkdirAll(filepath.Dir(configPath), 0700)
 		if err != nil {
-			fmt.Printf("Failed to create config directory: %v\n", err)
-			return
+			return fmt.Errorf("failed to create config directory: %v", err)
 		}
 
 		// Set up viper
-		viper.Set("falcon.client_id", answers.ClientID)
-		viper.Set("falcon.client_secret", answers.ClientSecret)
-		viper.Set("falcon.cloud_region", answers.CloudRegion)
+		viper.Set(key+".client_id", answers.ClientID)
+		viper.Set(key+".cloud_region", answers.CloudRegion)
+		viper.Set(key+".client_secret_backend", backend)
+
+		if backend == utils.SecretBackendPlaintext {
+			viper.Set(key+".client_secret", answers.ClientSecret)
+		} else {
+			store, err := utils.NewSecretStore(backend)
+			if err != nil {
+				return err
+			}
+			if err := store.Set(profileName, answers.ClientSecret); err != nil {
+				return fmt.Errorf("failed to store client secret: %v", err)
+			}
+
+			// Blank out a plaintext secret left by an earlier init
+			if viper.IsSet(key + ".client_secret") {
+				viper.Set(key+".client_secret", "")
+			}
+		}
+
+		// Make the first profile the active one
+		if profileName != utils.DefaultProfile && viper.GetString("active_profile") == "" && viper.GetString("falcon.client_id") == "" {
+			viper.Set("active_profile", profileName)
+		}
 
 		// Save the config
-		configPath := filepath.Join(falconDir, "config.yaml")
 		err = viper.WriteConfigAs(configPath)
 		if err != nil {
-			fmt.Printf("Failed to save config: %v\n", err)
-			return
+			return fmt.Errorf("failed to save config: %v", err)
 		}
 
-		fmt.Println("\nConfiguration saved successfully!")
+		fmt.Printf("\nConfiguration for profile '%s' saved successfully!\n", profileName)
 		fmt.Printf("Config file location: %s\n", configPath)
+		fmt.Printf("Client secret stored in: %s\n", backend)
+
+		return nil
 	},
 }
 
+// printScopes probes and prints the read scopes granted to the credentials being set up
+func printScopes(cmd *cobra.Command, answers initAnswers, token *utils.TokenResponse) error {
+	checks, err := utils.CheckScopes(answers.ClientID, answers.ClientSecret, answers.CloudRegion, token)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nGranted scopes:")
+	printer, err := output.NewFromFlags(cmd, scopeColumns)
+	if err != nil {
+		return err
+	}
+	for _, check := range checks {
+		if err := printer.Add(check); err != nil {
+			return err
+		}
+	}
+	return printer.Flush()
+}
+
+// initAnswers holds the credentials gathered by init
+type initAnswers struct {
+	ClientID     string `survey:"client_id"`
+	ClientSecret string `survey:"client_secret"`
+	CloudRegion  string `survey:"cloud_region"`
+}
+
+// credentialsFromFlags reads credentials from init's flags, falling back to
+// environment variables
+func credentialsFromFlags(cmd *cobra.Command) (initAnswers, error) {
+	clientID, _ := cmd.Flags().GetString("client-id")
+	region, _ := cmd.Flags().GetString("region")
+	secretStdin, _ := cmd.Flags().GetBool("client-secret-stdin")
+
+	answers := initAnswers{
+		ClientID:     clientID,
+		ClientSecret: os.Getenv("FALCON_CLIENT_SECRET"),
+		CloudRegion:  region,
+	}
+	if answers.ClientID == "" {
+		answers.ClientID = os.Getenv("FALCON_CLIENT_ID")
+	}
+	if answers.CloudRegion == "" {
+		answers.CloudRegion = os.Getenv("FALCON_CLOUD_REGION")
+	}
+
+	// The secret is read from stdin so it never appears in the process list or shell history
+	if secretStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return answers, fmt.Errorf("failed to read client secret from stdin: %v", err)
+		}
+		answers.ClientSecret = strings.TrimSpace(line)
+		if answers.ClientSecret == "" {
+			return answers, fmt.Errorf("client secret read from stdin is empty")
+		}
+	}
+
+	// Region defaults like the interactive prompt once credentials are given
+	if answers.CloudRegion == "" && answers.ClientID != "" && answers.ClientSecret != "" {
+		answers.CloudRegion = "us-1"
+	}
+
+	return answers, nil
+}
+
+// askCredentials prompts for the credentials that were not given
+func askCredentials(answers *initAnswers) error {
+	// Questions for the user
+	var qs []*survey.Question
+	if answers.ClientID == "" {
+		qs = append(qs, &survey.Question{
+			Name: "client_id",
+			Prompt: &survey.Input{
+				Message: "Enter your Falcon Client ID:",
+			},
+			Validate: func(val interface{}) error {
+				if str, ok := val.(string); !ok || len(str) == 0 {
+					return fmt.Errorf("Client ID cannot be empty")
+				}
+				return nil
+			},
+		})
+	}
+	if answers.ClientSecret == "" {
+		qs = append(qs, &survey.Question{
+			Name: "client_secret",
+			Prompt: &survey.Password{
+				Message: "Enter your Falcon Client Secret:",
+			},
+			Validate: func(val interface{}) error {
+				if str, ok := val.(string); !ok || len(str) == 0 {
+					return fmt.Errorf("Client Secret cannot be empty")
+				}
+				return nil
+			},
+		})
+	}
+	if answers.CloudRegion == "" {
+		qs = append(qs, &survey.Question{
+			Name: "cloud_region",
+			Prompt: &survey.Select{
+				Message: "Select your Falcon Cloud Region:",
+				Options: cloudRegions,
+				Default: "us-1",
+			},
+		})
+	}
+
+	// Perform the questions
+	if err := survey.Ask(qs, answers); err != nil {
+		return fmt.Errorf("failed to get answers: %v", err)
+	}
+	return nil
+}
+
 func init() {
-	// No flags needed for init command
+	InitCmd.Flags().String("secret-backend", "", fmt.Sprintf("Where to store the client secret (%s; default is keyring if available, otherwise file)", strings.Join(utils.SecretBackends, ", ")))
+	InitCmd.Flags().String("client-id", "", "Falcon API client ID (or set FALCON_CLIENT_ID)")
+	InitCmd.Flags().Bool("client-secret-stdin", false, "Read the client secret from stdin (or set FALCON_CLIENT_SECRET)")
+	InitCmd.Flags().String("region", "", fmt.Sprintf("Falcon cloud region: %s (or set FALCON_CLOUD_REGION)", strings.Join(cloudRegions, ", ")))
+	InitCmd.Flags().Bool("force", false, "Overwrite an existing profile without asking")
+	InitCmd.Flags().Bool("check-scopes", false, "Before saving, list which read scopes the API client has been granted")
 }
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ProfileInfo describes a profile in the output of profiles list
+type ProfileInfo struct {
+	Name        string `json:"name"`
+	ClientID    string `json:"client_id"`
+	CloudRegion string `json:"cloud_region"`
+	Active      bool   `json:"active"`
+}
+
+// profileColumns are the columns shown by profiles list
+var profileColumns = []output.Column{
+	{Header: "NAME", Field: "name"},
+	{Header: "CLIENT ID", Field: "client_id"},
+	{Header: "REGION", Field: "cloud_region"},
+	{Header: "ACTIVE", Field: "active"},
+}
+
+// configCmd represents the base config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage Falcon CLI configuration",
+	Long:  `Manage Falcon CLI configuration, including named credential profiles.`,
+}
+
+// profilesCmd represents the config profiles command
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage named credential profiles",
+	Long: `Manage named credential profiles, each holding the client ID, client secret and cloud region
+of one Falcon tenant. Add a profile with 'falcon-cli init --profile NAME'.
+
+The profile used by a command is chosen by the --profile flag, then the FALCON_PROFILE
+environment variable, then the profile selected with 'config profiles use'. Credentials
+stored under the falcon key are available as the 'default' profile.`,
+}
+
+// profilesListCmd represents the config profiles list command
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names := utils.ProfileNames()
+		if len(names) == 0 {
+			fmt.Println("No profiles configured. Please run 'falcon-cli init' first")
+			return nil
+		}
+
+		printer, err := output.NewFromFlags(cmd, profileColumns)
+		if err != nil {
+			return err
+		}
+
+		active := utils.ActiveProfileName()
+		for _, name := range names {
+			profile, err := utils.GetProfile(name)
+			if err != nil {
+				return err
+			}
+			info := ProfileInfo{
+				Name:        profile.Name,
+				ClientID:    profile.ClientID,
+				CloudRegion: profile.CloudRegion,
+				Active:      name == active,
+			}
+			if err := printer.Add(info); err != nil {
+				return err
+			}
+		}
+		return printer.Flush()
+	},
+}
+
+// CompleteProfileNames offers the names of the configured profiles, for --profile
+func CompleteProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return utils.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileArg completes a single profile name argument
+func completeProfileArg(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return CompleteProfileNames(cmd, args, toComplete)
+}
+
+// profilesUseCmd represents the config profiles use command
+var profilesUseCmd = &cobra.Command{
+	Use:               "use NAME",
+	Short:             "Set the profile used by default",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if _, err := utils.GetProfile(name); err != nil {
+			return err
+		}
+
+		viper.Set("active_profile", name)
+		if err := viper.WriteConfig(); err != nil {
+			return fmt.Errorf("error saving config: %v", err)
+		}
+
+		fmt.Printf("Now using profile '%s'\n", name)
+		return nil
+	},
+}
+
+// profilesDeleteCmd represents the config profiles delete command
+var profilesDeleteCmd = &cobra.Command{
+	Use:               "delete NAME",
+	Short:             "Delete a profile",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		profile, err := utils.GetProfile(name)
+		if err != nil {
+			return err
+		}
+
+		// Remove the secret from its backend first, so it is not orphaned
+		if profile.SecretBackend != "" && profile.SecretBackend != utils.SecretBackendPlaintext {
+			store, err := utils.NewSecretStore(profile.SecretBackend)
+			if err != nil {
+				return err
+			}
+			if err := store.Delete(name); err != nil {
+				return err
+			}
+		}
+
+		// A cached token would otherwise stay usable until it expires
+		if profile.ClientID != "" {
+			if err := utils.RemoveCachedToken(profile.ClientID, profile.CloudRegion); err != nil {
+				return err
+			}
+		}
+
+		err = utils.RewriteConfig(func(settings map[string]interface{}) error {
+			if name == utils.DefaultProfile {
+				// Keep other settings under the falcon key, such as retry settings
+				if falcon, ok := profileSettings(settings, name); ok {
+					delete(falcon, "client_id")
+					delete(falcon, "client_secret")
+					delete(falcon, "client_secret_backend")
+					delete(falcon, "cloud_region")
+				}
+			} else if profiles, ok := settings["profiles"].(map[string]interface{}); ok {
+				delete(profiles, name)
+			}
+
+			// Don't leave the deleted profile selected
+			if settings["active_profile"] == name {
+				delete(settings, "active_profile")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Deleted profile '%s'\n", name)
+		return nil
+	},
+}
+
+// GetCommand returns the config command
+func GetCommand() *cobra.Command {
+	profilesCmd.AddCommand(profilesListCmd)
+	profilesCmd.AddCommand(profilesUseCmd)
+	profilesCmd.AddCommand(profilesDeleteCmd)
+
+	configCmd.AddCommand(profilesCmd)
+	configCmd.AddCommand(migrateSecretsCmd)
+
+	return configCmd
+}
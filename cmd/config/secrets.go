@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// migrateSecretsCmd represents the config migrate-secrets command
+var migrateSecretsCmd = &cobra.Command{
+	Use:   "migrate-secrets",
+	Short: "Move plaintext client secrets out of the config file",
+	Long: `Move client secrets stored in plaintext in the config file into the OS keyring or the
+passphrase-encrypted secrets file, then remove them from the config file.
+
+Every profile with a plaintext secret is migrated. The passphrase for the encrypted file is
+read from FALCON_CLI_PASSPHRASE, or prompted for.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backend, _ := cmd.Flags().GetString("backend")
+		if backend == "" {
+			backend = utils.SecretBackendFile
+			if utils.KeyringAvailable() {
+				backend = utils.SecretBackendKeyring
+			}
+		}
+
+		store, err := utils.NewSecretStore(backend)
+		if err != nil {
+			return err
+		}
+
+		// Store every plaintext secret before touching the config file
+		var migrated []string
+		for _, name := range utils.ProfileNames() {
+			profile, err := utils.GetProfile(name)
+			if err != nil {
+				return err
+			}
+			if profile.ClientSecret == "" {
+				continue
+			}
+			if err := store.Set(name, profile.ClientSecret); err != nil {
+				return fmt.Errorf("error migrating profile '%s': %v", name, err)
+			}
+			migrated = append(migrated, name)
+		}
+
+		if len(migrated) == 0 {
+			fmt.Println("No plaintext client secrets found")
+			return nil
+		}
+
+		err = utils.RewriteConfig(func(settings map[string]interface{}) error {
+			for _, name := range migrated {
+				section, ok := profileSettings(settings, name)
+				if !ok {
+					continue
+				}
+				delete(section, "client_secret")
+				section["client_secret_backend"] = backend
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Moved client secrets for %s to the %s backend\n", strings.Join(migrated, ", "), backend)
+		return nil
+	},
+}
+
+// profileSettings returns the settings map holding the named profile
+func profileSettings(settings map[string]interface{}, name string) (map[string]interface{}, bool) {
+	if name == utils.DefaultProfile {
+		section, ok := settings["falcon"].(map[string]interface{})
+		return section, ok
+	}
+
+	profiles, ok := settings["profiles"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	section, ok := profiles[name].(map[string]interface{})
+	return section, ok
+}
+
+func init() {
+	migrateSecretsCmd.Flags().String("backend", "", "Backend to move secrets to (keyring or file; default is keyring if available, otherwise file)")
+}
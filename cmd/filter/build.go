@@ -0,0 +1,259 @@
+package filter
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/fql"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// QueryEndpoints maps filter types to the query endpoint their filters are used with
+var QueryEndpoints = map[string]string{
+	"hosts":      "/devices/queries/devices/v1",
+	"alerts":     "/alerts/queries/alerts/v2",
+	"detections": "/detects/queries/detects/v1",
+	"incidents":  "/incidents/queries/incidents/v1",
+}
+
+// builderOperator is an operator offered by filter build
+type builderOperator struct {
+	Label    string
+	Operator string
+}
+
+// builderOperators are the operators offered by filter build, in menu order
+var builderOperators = []builderOperator{
+	{"equals", ""},
+	{"does not equal", "!"},
+	{"matches wildcard (e.g. web-*)", "*"},
+	{"does not match wildcard", "!*"},
+	{"contains text", "~"},
+	{"does not contain text", "!~"},
+	{"greater than", ">"},
+	{"greater than or equal to", ">="},
+	{"less than", "<"},
+	{"less than or equal to", "<="},
+}
+
+// Choices offered after each condition
+const (
+	nextAnd  = "Add another condition that must also match (AND)"
+	nextOr   = "Add an alternative condition (OR)"
+	nextDone = "Done"
+)
+
+// buildCmd represents the filter build command
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build a filter interactively",
+	Long: `Build a filter step by step by choosing fields, operators and values, then save it like
+'filter save'. Fields with a fixed set of values, such as platform_name and status, offer
+those values to pick from. The filter built so far is shown after each condition.
+
+Conditions joined with AND bind more tightly than those joined with OR, so
+a AND b OR c matches hosts matching both a and b, or matching c.`,
+	Example: `  falcon-cli filter build --type hosts
+  falcon-cli filter build --type hosts --name windows-servers --preview`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filterType, _ := cmd.Flags().GetString("type")
+		name, _ := cmd.Flags().GetString("name")
+		description, _ := cmd.Flags().GetString("description")
+
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("filter build needs an interactive terminal; use 'filter save --filter' instead")
+		}
+
+		fields := fql.Fields(filterType)
+		if fields == nil {
+			return fmt.Errorf("no field catalog for type '%s' (supported types: %s)", filterType, strings.Join(fql.Types(), ", "))
+		}
+
+		// Build the expression one condition at a time
+		var expr strings.Builder
+		for {
+			condition, err := askCondition(filterType, fields)
+			if err != nil {
+				return err
+			}
+			expr.WriteString(condition)
+			fmt.Printf("\nFilter: %s\n\n", expr.String())
+
+			next := ""
+			prompt := &survey.Select{
+				Message: "What next?",
+				Options: []string{nextAnd, nextOr, nextDone},
+			}
+			if err := survey.AskOne(prompt, &next); err != nil {
+				return fmt.Errorf("failed to get answer: %v", err)
+			}
+			if next == nextDone {
+				break
+			}
+			if next == nextAnd {
+				expr.WriteString("+")
+			} else {
+				expr.WriteString(",")
+			}
+		}
+
+		if err := ValidateExpression(cmd, expr.String(), filterType); err != nil {
+			return err
+		}
+
+		// Optionally show how many results the filter matches
+		preview := false
+		if cmd.Flags().Changed("preview") {
+			preview, _ = cmd.Flags().GetBool("preview")
+		} else if _, ok := QueryEndpoints[filterType]; ok {
+			prompt := &survey.Confirm{Message: "Preview how many results match?", Default: true}
+			if err := survey.AskOne(prompt, &preview); err != nil {
+				return fmt.Errorf("failed to get answer: %v", err)
+			}
+		}
+		if preview {
+			count, err := countMatches(filterType, expr.String())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("The filter matches %d %s\n\n", count, filterType)
+		}
+
+		save := true
+		if err := survey.AskOne(&survey.Confirm{Message: "Save this filter?", Default: true}, &save); err != nil {
+			return fmt.Errorf("failed to get answer: %v", err)
+		}
+		if !save {
+			fmt.Println(expr.String())
+			return nil
+		}
+
+		answers := struct {
+			Name        string
+			Description string
+		}{Name: name, Description: description}
+		var qs []*survey.Question
+		if name == "" {
+			qs = append(qs, &survey.Question{
+				Name:     "name",
+				Prompt:   &survey.Input{Message: "Filter name:"},
+				Validate: survey.Required,
+			})
+		}
+		if description == "" {
+			qs = append(qs, &survey.Question{
+				Name:   "description",
+				Prompt: &survey.Input{Message: "Description (optional):"},
+			})
+		}
+		if err := survey.Ask(qs, &answers); err != nil {
+			return fmt.Errorf("failed to get answers: %v", err)
+		}
+
+		newFilter := Filter{
+			Name:        answers.Name,
+			Type:        filterType,
+			Description: answers.Description,
+			Filter:      expr.String(),
+		}
+		return saveFilter(cmd, newFilter, "Built with filter build")
+	},
+}
+
+// askCondition prompts for a field, an operator and a value, returning the
+// condition as FQL
+func askCondition(filterType string, fields []string) (string, error) {
+	field := ""
+	if err := survey.AskOne(&survey.Select{Message: "Field:", Options: fields, PageSize: 15}, &field); err != nil {
+		return "", fmt.Errorf("failed to get answer: %v", err)
+	}
+
+	// Prefixed fields such as device.* need the rest of the field name
+	if prefix, ok := strings.CutSuffix(field, "*"); ok {
+		prompt := &survey.Input{Message: "Field name:", Default: prefix}
+		validate := func(val interface{}) error {
+			if s, _ := val.(string); !strings.HasPrefix(s, prefix) || len(s) == len(prefix) {
+				return fmt.Errorf("enter a field under %s, e.g. %shostname", prefix, prefix)
+			}
+			return nil
+		}
+		if err := survey.AskOne(prompt, &field, survey.WithValidator(validate)); err != nil {
+			return "", fmt.Errorf("failed to get answer: %v", err)
+		}
+	}
+
+	labels := make([]string, len(builderOperators))
+	for i, op := range builderOperators {
+		labels[i] = op.Label
+	}
+	choice := 0
+	if err := survey.AskOne(&survey.Select{Message: "Operator:", Options: labels}, &choice); err != nil {
+		return "", fmt.Errorf("failed to get answer: %v", err)
+	}
+	operator := builderOperators[choice].Operator
+
+	// Offer the known values of enumerated fields
+	if values := fql.Values(filterType, field); values != nil && (operator == "" || operator == "!") {
+		var selected []string
+		prompt := &survey.MultiSelect{Message: "Values (any of):", Options: values}
+		if err := survey.AskOne(prompt, &selected, survey.WithValidator(survey.MinItems(1))); err != nil {
+			return "", fmt.Errorf("failed to get answer: %v", err)
+		}
+		return field + ":" + operator + formatValues(selected), nil
+	}
+
+	value := ""
+	help := "Dates can be given as 2024-01-31 or relative to now, e.g. now-7d"
+	prompt := &survey.Input{Message: "Value:", Help: help}
+	if err := survey.AskOne(prompt, &value, survey.WithValidator(survey.Required)); err != nil {
+		return "", fmt.Errorf("failed to get answer: %v", err)
+	}
+	return field + ":" + operator + formatValue(value), nil
+}
+
+// formatValues renders one value as is, and several as an FQL list
+func formatValues(values []string) string {
+	if len(values) == 1 {
+		return formatValue(values[0])
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = formatValue(v)
+	}
+	return "[" + strings.Join(quoted, ",") + "]"
+}
+
+// formatValue quotes a value unless it is a number or a boolean
+func formatValue(value string) string {
+	if value == "true" || value == "false" {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return fql.Quote(value)
+}
+
+// countMatches returns how many results of a filter type a filter matches
+func countMatches(filterType, expr string) (int, error) {
+	endpoint, ok := QueryEndpoints[filterType]
+	if !ok {
+		return 0, fmt.Errorf("cannot preview filters of type '%s'", filterType)
+	}
+
+	client, err := utils.NewFalconClient()
+	if err != nil {
+		return 0, fmt.Errorf("error creating Falcon client: %w", err)
+	}
+
+	count, _, err := client.Count(endpoint, map[string]string{"filter": expr})
+	if err != nil {
+		return 0, fmt.Errorf("error counting matches: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils/fql"
+	"github.com/spf13/cobra"
+)
+
+// completeFilterNames offers saved filter names with their descriptions. The
+// type is fixed by filterType, or else taken from the command's --type flag
+// when it is set. Only the last word of a partly typed expression such as
+// "windows-servers AND NOT cr" is completed.
+func completeFilterNames(personalOnly bool, filterType string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		wantType := filterType
+		if wantType == "" {
+			if flag := cmd.Flags().Lookup("type"); flag != nil {
+				wantType = flag.Value.String()
+			}
+		}
+
+		var filters []Filter
+		var err error
+		if personalOnly {
+			filters, err = readFilters()
+		} else {
+			filters, err = LoadFilters()
+		}
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		prefix := toComplete[:strings.LastIndexAny(toComplete, " ()")+1]
+		seen := make(map[string]bool)
+		var completions []cobra.Completion
+		for _, f := range filters {
+			if (wantType != "" && f.Type != wantType) || seen[f.Name] {
+				continue
+			}
+			seen[f.Name] = true
+			if f.Description == "" {
+				completions = append(completions, prefix+f.Name)
+			} else {
+				completions = append(completions, cobra.CompletionWithDesc(prefix+f.Name, f.Description))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CompleteFilterNames offers the names of saved filters of a type, for flags
+// such as --filter-name
+func CompleteFilterNames(filterType string) cobra.CompletionFunc {
+	return completeFilterNames(false, filterType)
+}
+
+// CompleteFilterTypes offers the filter types with a field catalog and the
+// types of saved filters
+func CompleteFilterTypes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	seen := make(map[string]bool)
+	for _, t := range fql.Types() {
+		seen[t] = true
+	}
+	if filters, err := LoadFilters(); err == nil {
+		for _, f := range filters {
+			seen[f.Type] = true
+		}
+	}
+
+	types := make([]string, 0, len(seen))
+	for t := range seen {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCatalogTypes offers the filter types with a field catalog
+func completeCatalogTypes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return fql.Types(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFirstArg completes only the first positional argument with fn
+func completeFirstArg(fn cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// completeSortOrders offers the sort orders accepted by filter list
+func completeSortOrders(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var orders []cobra.Completion
+	for _, field := range listSortFields {
+		orders = append(orders, field+".asc", field+".desc")
+	}
+	return orders, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerCompletions sets up completion of filter names and types for the filter commands
+func registerCompletions() {
+	for _, cmd := range []*cobra.Command{saveCmd, listCmd, deleteCmd, exportCmd, importCmd, testCmd, historyCmd, diffCmd, rollbackCmd} {
+		cmd.RegisterFlagCompletionFunc("type", CompleteFilterTypes)
+	}
+	buildCmd.RegisterFlagCompletionFunc("type", completeCatalogTypes)
+
+	saveCmd.RegisterFlagCompletionFunc("name", completeFilterNames(true, ""))
+	deleteCmd.RegisterFlagCompletionFunc("name", completeFilterNames(true, ""))
+
+	testCmd.ValidArgsFunction = completeFirstArg(completeFilterNames(false, ""))
+	historyCmd.ValidArgsFunction = completeFirstArg(completeFilterNames(true, ""))
+	diffCmd.ValidArgsFunction = completeFirstArg(completeFilterNames(true, ""))
+	rollbackCmd.ValidArgsFunction = completeFirstArg(completeFilterNames(true, ""))
+
+	listCmd.RegisterFlagCompletionFunc("sort", completeSortOrders)
+
+	exportCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"yaml", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	importCmd.RegisterFlagCompletionFunc("on-conflict", cobra.FixedCompletions([]string{ConflictSkip, ConflictOverwrite, ConflictRename}, cobra.ShellCompDirectiveNoFileComp))
+}
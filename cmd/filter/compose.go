@@ -0,0 +1,308 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils/fql"
+	"github.com/spf13/cobra"
+)
+
+// nameExpr is a boolean expression over saved filter names, such as
+// "windows-servers AND NOT critical-prod"
+type nameExpr struct {
+	op    string // "AND", "OR", "NOT", or "" for a filter name
+	name  string
+	terms []*nameExpr
+}
+
+// nameParser parses --filter-name expressions
+type nameParser struct {
+	input  string
+	tokens []string
+	pos    int
+}
+
+// tokenizeNames splits a --filter-name expression into names, keywords and parentheses
+func tokenizeNames(input string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range input {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// parseNameExpr parses a --filter-name expression. AND binds more tightly
+// than OR, and NOT more tightly than both; keywords are case-insensitive.
+func parseNameExpr(input string) (*nameExpr, error) {
+	p := &nameParser{input: input, tokens: tokenizeNames(input)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty --filter-name expression")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid --filter-name expression '%s': unexpected '%s'", input, p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+func (p *nameParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// keyword reports whether the next token is the given operator keyword
+func (p *nameParser) keyword(kw string) bool {
+	return strings.EqualFold(p.peek(), kw)
+}
+
+func (p *nameParser) parseOr() (*nameExpr, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	terms := []*nameExpr{first}
+	for p.keyword("OR") {
+		p.pos++
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return &nameExpr{op: "OR", terms: terms}, nil
+}
+
+func (p *nameParser) parseAnd() (*nameExpr, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	terms := []*nameExpr{first}
+	for p.keyword("AND") {
+		p.pos++
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return &nameExpr{op: "AND", terms: terms}, nil
+}
+
+func (p *nameParser) parseUnary() (*nameExpr, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("invalid --filter-name expression '%s': expected a filter name at the end", p.input)
+	case strings.EqualFold(tok, "NOT"):
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &nameExpr{op: "NOT", terms: []*nameExpr{x}}, nil
+	case tok == "(":
+		p.pos++
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("invalid --filter-name expression '%s': missing ')'", p.input)
+		}
+		p.pos++
+		return x, nil
+	case tok == ")" || strings.EqualFold(tok, "AND") || strings.EqualFold(tok, "OR"):
+		return nil, fmt.Errorf("invalid --filter-name expression '%s': expected a filter name before '%s'", p.input, tok)
+	}
+	p.pos++
+	return &nameExpr{name: tok}, nil
+}
+
+// names returns the filter names used in the expression
+func (e *nameExpr) names() []string {
+	if e.op == "" {
+		return []string{e.name}
+	}
+	var names []string
+	for _, t := range e.terms {
+		names = append(names, t.names()...)
+	}
+	return names
+}
+
+// build converts the expression to FQL using the parsed saved filters
+func (e *nameExpr) build(resolved map[string]fql.Node) fql.Node {
+	if e.op == "" {
+		return resolved[e.name]
+	}
+	terms := make([]fql.Node, len(e.terms))
+	for i, t := range e.terms {
+		terms[i] = t.build(resolved)
+	}
+	switch e.op {
+	case "AND":
+		return fql.AndOf(terms...)
+	case "OR":
+		return fql.OrOf(terms...)
+	}
+	return fql.Negate(terms[0])
+}
+
+// lookup finds a saved filter by name and type
+func lookup(filters []Filter, name, filterType string) (Filter, error) {
+	for _, f := range filters {
+		if f.Name == name && f.Type == filterType {
+			return f, nil
+		}
+	}
+	return Filter{}, fmt.Errorf("filter '%s' not found for type '%s'", name, filterType)
+}
+
+// FromFlags returns the filter selected by --filter, --filter-name and --param,
+// as merged by Compose
+func FromFlags(cmd *cobra.Command, filterType string) (string, error) {
+	filterValue, _ := cmd.Flags().GetString("filter")
+	nameExprs, _ := cmd.Flags().GetStringArray("filter-name")
+	pairs, _ := cmd.Flags().GetStringArray("param")
+
+	values, err := ParseParamValues(pairs)
+	if err != nil {
+		return "", err
+	}
+	if len(values) > 0 && len(nameExprs) == 0 {
+		return "", fmt.Errorf("--param can only be used with --filter-name")
+	}
+	return Compose(cmd, filterType, filterValue, nameExprs, values)
+}
+
+// Compose merges an ad-hoc filter and saved filters into one FQL expression.
+//
+// Each of nameExprs is a saved filter name or a boolean expression of names
+// using AND, OR, NOT and parentheses. All of nameExprs and filterValue must
+// match, and are merged into a single parenthesized FQL expression. Template
+// parameters in values apply to every template that declares them.
+func Compose(cmd *cobra.Command, filterType, filterValue string, nameExprs []string, values map[string]string) (string, error) {
+
+	// Collect the parts that must all match, each already validated
+	var nodes []fql.Node
+	if len(nameExprs) > 0 {
+		filters, err := LoadFilters()
+		if err != nil {
+			return "", err
+		}
+
+		var exprs []*nameExpr
+		used := make(map[string]bool)
+		rendered := make(map[string]string)
+		for _, input := range nameExprs {
+			expr, err := parseNameExpr(input)
+			if err != nil {
+				return "", err
+			}
+			exprs = append(exprs, expr)
+
+			for _, name := range expr.names() {
+				if _, ok := rendered[name]; ok {
+					continue
+				}
+				f, err := lookup(filters, name, filterType)
+				if err != nil {
+					return "", err
+				}
+				own := make(map[string]string)
+				for _, p := range f.Params {
+					if v, ok := values[p.Name]; ok {
+						own[p.Name] = v
+						used[p.Name] = true
+					}
+				}
+				rendered[name], err = f.Render(own)
+				if err != nil {
+					return "", err
+				}
+				if err := ValidateExpression(cmd, rendered[name], filterType); err != nil {
+					return "", fmt.Errorf("saved filter '%s' is invalid: %w", name, err)
+				}
+			}
+		}
+
+		var unused []string
+		for name := range values {
+			if !used[name] {
+				unused = append(unused, name)
+			}
+		}
+		if len(unused) > 0 {
+			sort.Strings(unused)
+			return "", fmt.Errorf("no selected filter has a parameter named %s", strings.Join(unused, ", "))
+		}
+
+		// A single plain name needs no merging, so it is passed through as saved
+		if len(exprs) == 1 && exprs[0].op == "" && filterValue == "" {
+			return rendered[exprs[0].name], nil
+		}
+
+		resolved := make(map[string]fql.Node)
+		for name, expr := range rendered {
+			node, err := fql.Parse(expr)
+			if err != nil {
+				return "", fmt.Errorf("saved filter '%s' cannot be combined: %w", name, err)
+			}
+			resolved[name] = node
+		}
+		for _, expr := range exprs {
+			nodes = append(nodes, expr.build(resolved))
+		}
+	}
+
+	if filterValue != "" {
+		if err := ValidateExpression(cmd, filterValue, filterType); err != nil {
+			return "", err
+		}
+		if len(nodes) == 0 {
+			return filterValue, nil
+		}
+		node, err := fql.Parse(filterValue)
+		if err != nil {
+			return "", fmt.Errorf("--filter cannot be combined: %w", err)
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 0 {
+		return "", nil
+	}
+	return fql.AndOf(nodes...).String(), nil
+}
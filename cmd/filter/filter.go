@@ -2,17 +2,69 @@ package filter
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/HARSH16DAWAR/falcon-cli/utils/fql"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // Filter represents a saved filter configuration
 type Filter struct {
-	Name        string `json:"name"`
-	Type        string `json:"type"`
-	Description string `json:"description"`
-	Filter      string `json:"filter"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Filter      string    `json:"filter"`
+	Params      []Param   `json:"params,omitempty" yaml:"params,omitempty"`   // Set for templates
+	History     []Version `json:"history,omitempty" yaml:"history,omitempty"` // Every saved version, oldest first
+	Source      string    `json:"-" yaml:"-" mapstructure:"-"`                // SourcePersonal or a library file path
+}
+
+// upsert replaces the filter with the same name and type as f, or appends f,
+// reporting whether an existing filter was replaced
+func upsert(filters []Filter, f Filter) ([]Filter, bool) {
+	for i, existing := range filters {
+		if existing.Name == f.Name && existing.Type == f.Type {
+			filters[i] = f
+			return filters, true
+		}
+	}
+	return append(filters, f), false
+}
+
+// ValidateExpression checks a filter expression for syntax errors and unknown
+// fields of the given type, unless --no-validate is set
+func ValidateExpression(cmd *cobra.Command, expr, filterType string) error {
+	if noValidate, _ := cmd.Flags().GetBool("no-validate"); noValidate {
+		return nil
+	}
+	_, err := fql.Validate(expr, filterType)
+	return err
+}
+
+// validateFilter checks a filter before it is saved. Templates are checked with
+// sample parameter values substituted for their placeholders.
+func validateFilter(cmd *cobra.Command, f Filter) error {
+	if err := f.checkTemplate(); err != nil {
+		return err
+	}
+	if !f.IsTemplate() {
+		return ValidateExpression(cmd, f.Filter, f.Type)
+	}
+
+	samples := make(map[string]string)
+	for _, p := range f.Params {
+		samples[p.Name] = p.sample()
+	}
+	rendered, err := f.Render(samples)
+	if err != nil {
+		return err
+	}
+	if err := ValidateExpression(cmd, rendered, f.Type); err != nil {
+		return fmt.Errorf("template is invalid with sample parameter values: %w", err)
+	}
+	return nil
 }
 
 // filterCmd represents the base filter command
@@ -26,12 +78,31 @@ var filterCmd = &cobra.Command{
 var saveCmd = &cobra.Command{
 	Use:   "save",
 	Short: "Save a filter for later use",
-	Long:  `Save a filter with a name and description for later use in various Falcon CLI commands.`,
+	Long: `Save a filter with a name and description for later use in various Falcon CLI commands.
+
+The filter is checked for FQL syntax errors and, for the hosts, alerts, detections, incidents
+and host-groups types, for unknown field names before it is saved. Use --no-validate to save it as is.
+
+A filter becomes a template when it contains {{name}} placeholders. Declare each one with
+--param name:type[:choices][=default], where type is string, enum, date, duration or ip.
+Values are given when the filter is used, e.g. hosts --filter-name NAME --param name=value.`,
+	Example: `  falcon-cli filter save --name windows-servers --type hosts --filter "platform_name:'Windows'"
+
+  # A template with a required hostname and a defaulted lookback window
+  falcon-cli filter save --name recent-host --type hosts \
+    --filter "hostname:'{{host}}'+last_seen:>'{{since}}'" \
+    --param host:string --param since:duration=7d
+
+  # An enum parameter
+  falcon-cli filter save --name by-platform --type hosts \
+    --filter "platform_name:'{{platform}}'" --param "platform:enum:Windows|Linux|Mac"`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name, _ := cmd.Flags().GetString("name")
 		filterType, _ := cmd.Flags().GetString("type")
 		description, _ := cmd.Flags().GetString("description")
 		filterValue, _ := cmd.Flags().GetString("filter")
+		paramSpecs, _ := cmd.Flags().GetStringArray("param")
+		note, _ := cmd.Flags().GetString("note")
 
 		// Create new filter
 		newFilter := Filter{
@@ -40,65 +111,196 @@ var saveCmd = &cobra.Command{
 			Description: description,
 			Filter:      filterValue,
 		}
+		for _, spec := range paramSpecs {
+			param, err := ParseParamSpec(spec)
+			if err != nil {
+				return err
+			}
+			newFilter.Params = append(newFilter.Params, param)
+		}
+
+		return saveFilter(cmd, newFilter, note)
+	},
+}
+
+// saveFilter validates a filter and saves it, replacing a personal filter with
+// the same name and type and recording the change in its history
+func saveFilter(cmd *cobra.Command, newFilter Filter, note string) error {
+	if err := validateFilter(cmd, newFilter); err != nil {
+		return err
+	}
+
+	// Get existing filters; saving over a config that could not be read would lose them
+	filters, err := readFilters()
+	if err != nil {
+		return err
+	}
+
+	// Update the filter with the same name and type, keeping its history, or add a new one
+	var prev *Filter
+	if i, err := findPersonal(filters, newFilter.Name, newFilter.Type); err == nil {
+		prev = &filters[i]
+	}
+	newFilter = record(prev, newFilter, note)
+	filters, updated := upsert(filters, newFilter)
+	if err := writeFilters(filters); err != nil {
+		return fmt.Errorf("error saving filter: %v", err)
+	}
+
+	if updated {
+		versions := newFilter.Versions()
+		fmt.Printf("Updated filter '%s' for type '%s' (version %d)\n", newFilter.Name, newFilter.Type, versions[len(versions)-1].Version)
+		return nil
+	}
+	fmt.Printf("Saved filter '%s' for type '%s'\n", newFilter.Name, newFilter.Type)
+	return nil
+}
+
+// filterColumns are the columns shown by filter list
+var filterColumns = []output.Column{
+	{Header: "NAME", Field: "name"},
+	{Header: "TYPE", Field: "type"},
+	{Header: "VERSION", Field: "version"},
+	{Header: "SOURCE", Field: "source"},
+	{Header: "PARAMETERS", Field: "params"},
+	{Header: "DESCRIPTION", Field: "description"},
+	{Header: "FILTER", Field: "filter"},
+}
+
+// listSortFields are the fields filter list can sort by
+var listSortFields = []string{"name", "type", "version", "source"}
+
+// FilterInfo is how filter list shows a saved filter
+type FilterInfo struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Version     int      `json:"version"`
+	Source      string   `json:"source"`
+	Params      []string `json:"params,omitempty"`
+	Description string   `json:"description"`
+	Filter      string   `json:"filter"`
+}
 
-		// Get existing filters
-		var filters []Filter
-		if err := viper.UnmarshalKey("filters", &filters); err != nil {
-			filters = []Filter{}
+// info returns the filter as shown by filter list
+func (f Filter) info() FilterInfo {
+	versions := f.Versions()
+	info := FilterInfo{
+		Name:        f.Name,
+		Type:        f.Type,
+		Version:     versions[len(versions)-1].Version,
+		Source:      f.Source,
+		Description: f.Description,
+		Filter:      f.Filter,
+	}
+	for _, p := range f.Params {
+		info.Params = append(info.Params, p.String())
+	}
+	return info
+}
+
+// matches reports whether the filter's name, description or expression
+// contains search, ignoring case
+func (f Filter) matches(search string) bool {
+	search = strings.ToLower(search)
+	for _, s := range []string{f.Name, f.Description, f.Filter} {
+		if strings.Contains(strings.ToLower(s), search) {
+			return true
 		}
+	}
+	return false
+}
 
-		// Check if filter with same name and type already exists
-		for i, f := range filters {
-			if f.Name == name && f.Type == filterType {
-				// Update existing filter
-				filters[i] = newFilter
-				viper.Set("filters", filters)
-				if err := viper.WriteConfig(); err != nil {
-					return fmt.Errorf("error saving filter: %v", err)
-				}
-				fmt.Printf("Updated filter '%s' for type '%s'\n", name, filterType)
-				return nil
+// sortInfos sorts filters by a field[.asc|.desc] sort order, breaking ties by name and type
+func sortInfos(infos []FilterInfo, order string) error {
+	field, direction, _ := strings.Cut(order, ".")
+	if direction != "" && direction != "asc" && direction != "desc" {
+		return fmt.Errorf("invalid sort direction '%s' (use asc or desc)", direction)
+	}
+
+	var key func(FilterInfo) string
+	switch field {
+	case "name":
+		key = func(i FilterInfo) string { return i.Name }
+	case "type":
+		key = func(i FilterInfo) string { return i.Type }
+	case "source":
+		key = func(i FilterInfo) string { return i.Source }
+	case "version":
+		key = func(i FilterInfo) string { return fmt.Sprintf("%010d", i.Version) }
+	default:
+		return fmt.Errorf("invalid sort field '%s' (valid fields: %s)", field, strings.Join(listSortFields, ", "))
+	}
+
+	sort.SliceStable(infos, func(a, b int) bool {
+		ka, kb := key(infos[a]), key(infos[b])
+		if ka == kb {
+			if infos[a].Name != infos[b].Name {
+				return infos[a].Name < infos[b].Name
 			}
+			return infos[a].Type < infos[b].Type
 		}
-
-		// Add new filter
-		filters = append(filters, newFilter)
-		viper.Set("filters", filters)
-		if err := viper.WriteConfig(); err != nil {
-			return fmt.Errorf("error saving filter: %v", err)
+		if direction == "desc" {
+			return ka > kb
 		}
-
-		fmt.Printf("Saved filter '%s' for type '%s'\n", name, filterType)
-		return nil
-	},
+		return ka < kb
+	})
+	return nil
 }
 
 // listCmd represents the list filters command
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List saved filters",
-	Long:  `List all saved filters, optionally filtered by type.`,
+	Long: `List saved filters as a table, optionally only those of one type or those whose name,
+description or expression contains the --search text. Filters from filter libraries show the
+library file they come from as their source.
+
+Sort with --sort FIELD[.asc|.desc], where FIELD is name, type, version or source. Use
+--output json or yaml for a machine-readable list.`,
+	Example: `  falcon-cli filter list --type hosts
+  falcon-cli filter list --search windows --sort version.desc
+  falcon-cli filter list -o json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filterType, _ := cmd.Flags().GetString("type")
+		search, _ := cmd.Flags().GetString("search")
+		order, _ := cmd.Flags().GetString("sort")
+		format, _ := cmd.Flags().GetString("output")
 
-		var filters []Filter
-		if err := viper.UnmarshalKey("filters", &filters); err != nil {
-			return fmt.Errorf("error reading filters: %v", err)
+		filters, err := LoadFilters()
+		if err != nil {
+			return err
 		}
 
-		if len(filters) == 0 {
-			fmt.Println("No filters saved")
+		var infos []FilterInfo
+		for _, f := range filters {
+			if (filterType == "" || f.Type == filterType) && (search == "" || f.matches(search)) {
+				infos = append(infos, f.info())
+			}
+		}
+		if err := sortInfos(infos, order); err != nil {
+			return err
+		}
+
+		// Tell people rather than print an empty table; other formats print an empty list
+		if len(infos) == 0 && (format == "" || format == output.DefaultFormat) {
+			if len(filters) == 0 {
+				fmt.Println("No filters saved")
+			} else {
+				fmt.Println("No saved filters match")
+			}
 			return nil
 		}
 
-		fmt.Println("Saved filters:")
-		for _, f := range filters {
-			if filterType == "" || f.Type == filterType {
-				fmt.Printf("\nName: %s\nType: %s\nDescription: %s\nFilter: %s\n",
-					f.Name, f.Type, f.Description, f.Filter)
+		printer, err := output.NewFromFlags(cmd, filterColumns)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if err := printer.Add(info); err != nil {
+				return err
 			}
 		}
-		return nil
+		return printer.Flush()
 	},
 }
 
@@ -111,9 +313,9 @@ var deleteCmd = &cobra.Command{
 		name, _ := cmd.Flags().GetString("name")
 		filterType, _ := cmd.Flags().GetString("type")
 
-		var filters []Filter
-		if err := viper.UnmarshalKey("filters", &filters); err != nil {
-			return fmt.Errorf("error reading filters: %v", err)
+		filters, err := readFilters()
+		if err != nil {
+			return err
 		}
 
 		found := false
@@ -127,11 +329,15 @@ var deleteCmd = &cobra.Command{
 		}
 
 		if !found {
+			if all, err := LoadFilters(); err == nil {
+				if f, err := lookup(all, name, filterType); err == nil {
+					return fmt.Errorf("filter '%s' of type '%s' comes from the read-only library %s", name, filterType, f.Source)
+				}
+			}
 			return fmt.Errorf("filter '%s' of type '%s' not found", name, filterType)
 		}
 
-		viper.Set("filters", newFilters)
-		if err := viper.WriteConfig(); err != nil {
+		if err := writeFilters(newFilters); err != nil {
 			return fmt.Errorf("error deleting filter: %v", err)
 		}
 
@@ -147,12 +353,16 @@ func GetCommand() *cobra.Command {
 	saveCmd.Flags().String("type", "", "Type of the filter (e.g., hosts, detections)")
 	saveCmd.Flags().String("description", "", "Description of the filter")
 	saveCmd.Flags().String("filter", "", "Filter expression to save")
+	saveCmd.Flags().StringArray("param", nil, "Declare a template parameter as name:type[:choices][=default] (repeatable)")
+	saveCmd.Flags().String("note", "", "Describe the change in the filter's history")
 	saveCmd.MarkFlagRequired("name")
 	saveCmd.MarkFlagRequired("type")
 	saveCmd.MarkFlagRequired("filter")
 
 	// Add flags to list command
 	listCmd.Flags().String("type", "", "Filter type to list (optional)")
+	listCmd.Flags().String("search", "", "Only list filters whose name, description or expression contains this text")
+	listCmd.Flags().String("sort", "name", "Sort by name, type, version or source, optionally with .asc or .desc")
 
 	// Add flags to delete command
 	deleteCmd.Flags().String("name", "", "Name of the filter to delete")
@@ -160,10 +370,52 @@ func GetCommand() *cobra.Command {
 	deleteCmd.MarkFlagRequired("name")
 	deleteCmd.MarkFlagRequired("type")
 
+	// Add flags to export command
+	exportCmd.Flags().String("type", "", "Only export filters of this type")
+	exportCmd.Flags().String("format", "", "Output format: yaml or json (default from --file, otherwise yaml)")
+	exportCmd.Flags().String("file", "", "Write to this file instead of standard output")
+
+	// Add flags to import command
+	importCmd.Flags().String("on-conflict", ConflictSkip, "What to do when a filter already exists: skip, overwrite or rename")
+	importCmd.Flags().String("type", "", "Only import filters of this type")
+
+	// Add flags to build command
+	buildCmd.Flags().String("type", "", fmt.Sprintf("Type of the filter (%s)", strings.Join(fql.Types(), ", ")))
+	buildCmd.Flags().String("name", "", "Name to save the filter as (prompted for if not given)")
+	buildCmd.Flags().String("description", "", "Description of the filter (prompted for if not given)")
+	buildCmd.Flags().Bool("preview", false, "Show how many results match before saving (asked if not given)")
+	buildCmd.MarkFlagRequired("type")
+
+	// Add flags to test command
+	testCmd.Flags().String("type", "", "Type of the filter (needed when the name is used by several types)")
+	testCmd.Flags().Int("sample", 5, "Number of matching results to show")
+	testCmd.Flags().StringArray("param", nil, "Set a parameter of a filter template as NAME=VALUE (repeatable)")
+
+	// Add flags to history commands
+	historyCmd.Flags().String("type", "", "Type of the filter (needed when the name is used by several types)")
+	diffCmd.Flags().String("type", "", "Type of the filter (needed when the name is used by several types)")
+	rollbackCmd.Flags().String("type", "", "Type of the filter (needed when the name is used by several types)")
+	rollbackCmd.Flags().Int("version", 0, "Version to restore")
+	rollbackCmd.Flags().String("note", "", "Describe the rollback in the filter's history")
+	rollbackCmd.MarkFlagRequired("version")
+
 	// Add subcommands
 	filterCmd.AddCommand(saveCmd)
 	filterCmd.AddCommand(listCmd)
 	filterCmd.AddCommand(deleteCmd)
+	filterCmd.AddCommand(buildCmd)
+	filterCmd.AddCommand(testCmd)
+	filterCmd.AddCommand(historyCmd)
+	filterCmd.AddCommand(diffCmd)
+	filterCmd.AddCommand(rollbackCmd)
+	filterCmd.AddCommand(exportCmd)
+	filterCmd.AddCommand(importCmd)
+	libraryCmd.AddCommand(libraryAddCmd)
+	libraryCmd.AddCommand(libraryRemoveCmd)
+	libraryCmd.AddCommand(libraryListCmd)
+	filterCmd.AddCommand(libraryCmd)
+
+	registerCompletions()
 
 	return filterCmd
 }
@@ -0,0 +1,334 @@
+package filter
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/fql"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// Version is one saved revision of a filter
+type Version struct {
+	Version     int     `json:"version"`
+	Filter      string  `json:"filter"`
+	Description string  `json:"description"`
+	Params      []Param `json:"params,omitempty" yaml:"params,omitempty"`
+	Author      string  `json:"author,omitempty" yaml:"author,omitempty"`
+	Note        string  `json:"note,omitempty" yaml:"note,omitempty"`
+	SavedAt     string  `json:"saved_at,omitempty" yaml:"saved_at,omitempty" mapstructure:"saved_at"` // RFC 3339
+}
+
+// historyColumns are the columns shown by filter history
+var historyColumns = []output.Column{
+	{Header: "VERSION", Field: "version"},
+	{Header: "SAVED AT", Field: "saved_at"},
+	{Header: "AUTHOR", Field: "author"},
+	{Header: "NOTE", Field: "note"},
+	{Header: "FILTER", Field: "filter"},
+}
+
+// Versions returns the filter's history, oldest first. Filters saved before
+// history was kept are reported as a single version with no author or time.
+func (f Filter) Versions() []Version {
+	if len(f.History) > 0 {
+		return f.History
+	}
+	return []Version{{Version: 1, Filter: f.Filter, Description: f.Description, Params: f.Params}}
+}
+
+// version returns version n of the filter
+func (f Filter) version(n int) (Version, error) {
+	for _, v := range f.Versions() {
+		if v.Version == n {
+			return v, nil
+		}
+	}
+	latest := f.Versions()[len(f.Versions())-1].Version
+	return Version{}, fmt.Errorf("filter '%s' has no version %d (versions 1 to %d)", f.Name, n, latest)
+}
+
+// sameContent reports whether a version has the same filter, description and parameters as f
+func (v Version) sameContent(f Filter) bool {
+	return v.Filter == f.Filter && v.Description == f.Description && reflect.DeepEqual(v.Params, f.Params)
+}
+
+// record returns next with the history of prev, plus a new version for next
+// unless its content is unchanged. prev is nil for a new filter.
+func record(prev *Filter, next Filter, note string) Filter {
+	var history []Version
+	if prev != nil {
+		history = append(history, prev.Versions()...)
+		if history[len(history)-1].sameContent(next) && note == "" {
+			next.History = history
+			return next
+		}
+	}
+
+	number := 1
+	if len(history) > 0 {
+		number = history[len(history)-1].Version + 1
+	}
+	history = append(history, Version{
+		Version:     number,
+		Filter:      next.Filter,
+		Description: next.Description,
+		Params:      next.Params,
+		Author:      utils.ActiveProfileName(),
+		Note:        note,
+		SavedAt:     time.Now().UTC().Format(time.RFC3339),
+	})
+	next.History = history
+	return next
+}
+
+// findPersonal returns the index of a personal filter by name, and by type if
+// filterType is set. Without a type, the name must be unique across types.
+func findPersonal(filters []Filter, name, filterType string) (int, error) {
+	found := -1
+	for i, f := range filters {
+		if f.Name != name || (filterType != "" && f.Type != filterType) {
+			continue
+		}
+		if found >= 0 {
+			return -1, fmt.Errorf("filter '%s' exists for several types, use --type to choose one", name)
+		}
+		found = i
+	}
+	if found < 0 {
+		if filterType != "" {
+			return -1, fmt.Errorf("filter '%s' of type '%s' not found", name, filterType)
+		}
+		return -1, fmt.Errorf("filter '%s' not found", name)
+	}
+	return found, nil
+}
+
+// parseVersion parses a version number argument
+func parseVersion(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "v"))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid version '%s'", s)
+	}
+	return n, nil
+}
+
+// historyCmd represents the filter history command
+var historyCmd = &cobra.Command{
+	Use:   "history NAME",
+	Short: "Show the saved versions of a filter",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filterType, _ := cmd.Flags().GetString("type")
+
+		filters, err := readFilters()
+		if err != nil {
+			return err
+		}
+		i, err := findPersonal(filters, args[0], filterType)
+		if err != nil {
+			return err
+		}
+
+		printer, err := output.NewFromFlags(cmd, historyColumns)
+		if err != nil {
+			return err
+		}
+		for _, v := range filters[i].Versions() {
+			if err := printer.Add(v); err != nil {
+				return err
+			}
+		}
+		return printer.Flush()
+	},
+}
+
+// diffCmd represents the filter diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff NAME V1 [V2]",
+	Short: "Show the changes between two versions of a filter",
+	Long: `Show the changes between two versions of a filter. V2 defaults to the latest version.
+
+Filter expressions are compared term by term, splitting on the top-level '+'.`,
+	Example: `  falcon-cli filter diff windows-servers 1 3
+  falcon-cli filter diff windows-servers v2`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filterType, _ := cmd.Flags().GetString("type")
+
+		filters, err := readFilters()
+		if err != nil {
+			return err
+		}
+		i, err := findPersonal(filters, args[0], filterType)
+		if err != nil {
+			return err
+		}
+		f := filters[i]
+
+		n1, err := parseVersion(args[1])
+		if err != nil {
+			return err
+		}
+		versions := f.Versions()
+		n2 := versions[len(versions)-1].Version
+		if len(args) == 3 {
+			if n2, err = parseVersion(args[2]); err != nil {
+				return err
+			}
+		}
+
+		v1, err := f.version(n1)
+		if err != nil {
+			return err
+		}
+		v2, err := f.version(n2)
+		if err != nil {
+			return err
+		}
+
+		w := cmd.OutOrStdout()
+		fmt.Fprintf(w, "--- %s v%d%s\n", f.Name, v1.Version, versionLabel(v1))
+		fmt.Fprintf(w, "+++ %s v%d%s\n", f.Name, v2.Version, versionLabel(v2))
+		writeDiff(w, "filter", filterTerms(v1.Filter), filterTerms(v2.Filter))
+		writeDiff(w, "description", []string{v1.Description}, []string{v2.Description})
+		writeDiff(w, "params", paramLines(v1.Params), paramLines(v2.Params))
+		return nil
+	},
+}
+
+// rollbackCmd represents the filter rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback NAME",
+	Short: "Restore an earlier version of a filter",
+	Long: `Restore an earlier version of a filter. The restored content is saved as a new version,
+so the rollback itself can be undone.`,
+	Example: `  falcon-cli filter rollback windows-servers --version 2`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filterType, _ := cmd.Flags().GetString("type")
+		n, _ := cmd.Flags().GetInt("version")
+		note, _ := cmd.Flags().GetString("note")
+
+		filters, err := readFilters()
+		if err != nil {
+			return err
+		}
+		i, err := findPersonal(filters, args[0], filterType)
+		if err != nil {
+			return err
+		}
+
+		target, err := filters[i].version(n)
+		if err != nil {
+			return err
+		}
+
+		restored := filters[i]
+		restored.Filter = target.Filter
+		restored.Description = target.Description
+		restored.Params = target.Params
+		if note == "" {
+			note = fmt.Sprintf("Rollback to version %d", n)
+		}
+		filters[i] = record(&filters[i], restored, note)
+
+		if err := writeFilters(filters); err != nil {
+			return fmt.Errorf("error saving filter: %v", err)
+		}
+
+		versions := filters[i].Versions()
+		fmt.Printf("Restored filter '%s' for type '%s' to version %d as version %d\n",
+			restored.Name, restored.Type, n, versions[len(versions)-1].Version)
+		return nil
+	},
+}
+
+// versionLabel describes when and by whom a version was saved
+func versionLabel(v Version) string {
+	var parts []string
+	if v.SavedAt != "" {
+		parts = append(parts, v.SavedAt)
+	}
+	if v.Author != "" {
+		parts = append(parts, "by "+v.Author)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, " ") + ")"
+}
+
+// filterTerms splits an expression into its top-level AND terms
+func filterTerms(expr string) []string {
+	node, err := fql.Parse(expr)
+	if err != nil {
+		return []string{expr}
+	}
+	and, ok := node.(*fql.And)
+	if !ok {
+		return []string{expr}
+	}
+	terms := make([]string, len(and.Terms))
+	for i, t := range and.Terms {
+		terms[i] = t.String()
+		if _, ok := t.(*fql.Or); ok {
+			terms[i] = "(" + terms[i] + ")"
+		}
+	}
+	return terms
+}
+
+// paramLines describes parameters one per line
+func paramLines(params []Param) []string {
+	lines := make([]string, len(params))
+	for i, p := range params {
+		lines[i] = p.String()
+	}
+	return lines
+}
+
+// writeDiff writes a line diff of a field, or nothing if it is unchanged
+func writeDiff(w io.Writer, field string, a, b []string) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+
+	// Longest common subsequence table, filled from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "%s:\n", field)
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(w, "   %s\n", a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(w, "-  %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(w, "+  %s\n", b[j])
+			j++
+		}
+	}
+}
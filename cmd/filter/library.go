@@ -0,0 +1,282 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// SourcePersonal labels filters saved in the user's own config file
+const SourcePersonal = "personal"
+
+// filterFile is the layout of exported filter files and filter libraries
+type filterFile struct {
+	Filters []Filter `json:"filters" yaml:"filters"`
+}
+
+// readFilters returns the filters saved in the config file
+func readFilters() ([]Filter, error) {
+	var filters []Filter
+	if err := viper.UnmarshalKey("filters", &filters); err != nil {
+		return nil, fmt.Errorf("error reading filters: %v", err)
+	}
+	for i := range filters {
+		filters[i].Source = SourcePersonal
+	}
+	return filters, nil
+}
+
+// writeFilters saves filters to the config file
+func writeFilters(filters []Filter) error {
+	viper.Set("filters", filters)
+	return viper.WriteConfig()
+}
+
+// LoadFilters returns the personal filters followed by the filters of every
+// configured library. A personal filter hides a library filter with the same
+// name and type, and earlier libraries hide later ones. Libraries that cannot
+// be read are skipped with a warning.
+func LoadFilters() ([]Filter, error) {
+	filters, err := readFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range filters {
+		seen[f.Type+"/"+f.Name] = true
+	}
+
+	for _, path := range viper.GetStringSlice("filter_libraries") {
+		library, err := readLibrary(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping filter library %s: %v\n", path, err)
+			continue
+		}
+		for _, f := range library {
+			if seen[f.Type+"/"+f.Name] {
+				continue
+			}
+			seen[f.Type+"/"+f.Name] = true
+			filters = append(filters, f)
+		}
+	}
+	return filters, nil
+}
+
+// readLibrary reads the filters of a library file, or of every .yaml, .yml
+// and .json file in a library directory
+func readLibrary(path string) ([]Filter, error) {
+	path = expandHome(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !e.IsDir() {
+					files = append(files, filepath.Join(path, e.Name()))
+				}
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var filters []Filter
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parseFilterFile(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		for i := range parsed {
+			parsed[i].Source = file
+		}
+		filters = append(filters, parsed...)
+	}
+	return filters, nil
+}
+
+// parseFilterFile parses a YAML or JSON filter file, either a document with a
+// filters list or a bare list of filters
+func parseFilterFile(data []byte) ([]Filter, error) {
+	var doc filterFile
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		var list []Filter
+		if listErr := yaml.Unmarshal(data, &list); listErr != nil {
+			return nil, err
+		}
+		doc.Filters = list
+	}
+
+	for i, f := range doc.Filters {
+		if f.Name == "" || f.Type == "" || f.Filter == "" {
+			return nil, fmt.Errorf("filter %d is missing a name, type or filter", i+1)
+		}
+	}
+	return doc.Filters, nil
+}
+
+// encodeFilterFile renders filters as a YAML or JSON filter file
+func encodeFilterFile(filters []Filter, format string) ([]byte, error) {
+	doc := filterFile{Filters: filters}
+	if doc.Filters == nil {
+		doc.Filters = []Filter{}
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "yaml":
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	case "json":
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false) // Keep FQL operators such as > readable
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid format '%s' (valid formats: yaml, json)", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// libraryCmd represents the filter library command
+var libraryCmd = &cobra.Command{
+	Use:   "library",
+	Short: "Manage read-only filter libraries",
+	Long: `Filter libraries are YAML or JSON files, or directories of them, whose filters are merged
+with your personal filters. They are never modified by falcon-cli, so a team can share
+filters from a git checkout. A library file has the same layout as 'filter export' output.
+
+Personal filters take precedence over library filters with the same name and type.`,
+}
+
+// libraryAddCmd represents the filter library add command
+var libraryAddCmd = &cobra.Command{
+	Use:   "add PATH",
+	Short: "Add a filter library file or directory",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := filepath.Abs(expandHome(args[0]))
+		if err != nil {
+			return fmt.Errorf("error resolving path: %v", err)
+		}
+
+		filters, err := readLibrary(path)
+		if err != nil {
+			return fmt.Errorf("error reading filter library: %v", err)
+		}
+
+		libraries := viper.GetStringSlice("filter_libraries")
+		for _, l := range libraries {
+			if l == path {
+				return fmt.Errorf("filter library %s is already configured", path)
+			}
+		}
+
+		viper.Set("filter_libraries", append(libraries, path))
+		if err := viper.WriteConfig(); err != nil {
+			return fmt.Errorf("error saving config: %v", err)
+		}
+
+		fmt.Printf("Added filter library %s (%d filters)\n", path, len(filters))
+		return nil
+	},
+}
+
+// libraryRemoveCmd represents the filter library remove command
+var libraryRemoveCmd = &cobra.Command{
+	Use:   "remove PATH",
+	Short: "Stop using a filter library",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := expandHome(args[0])
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+
+		var kept []string
+		found := false
+		for _, l := range viper.GetStringSlice("filter_libraries") {
+			if l == path || l == args[0] {
+				found = true
+				continue
+			}
+			kept = append(kept, l)
+		}
+		if !found {
+			return fmt.Errorf("filter library %s is not configured", args[0])
+		}
+
+		viper.Set("filter_libraries", kept)
+		if err := viper.WriteConfig(); err != nil {
+			return fmt.Errorf("error saving config: %v", err)
+		}
+
+		fmt.Printf("Removed filter library %s\n", path)
+		return nil
+	},
+}
+
+// libraryListCmd represents the filter library list command
+var libraryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured filter libraries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		libraries := viper.GetStringSlice("filter_libraries")
+		if len(libraries) == 0 {
+			fmt.Println("No filter libraries configured")
+			return nil
+		}
+
+		for _, path := range libraries {
+			filters, err := readLibrary(path)
+			if err != nil {
+				fmt.Printf("%s (error: %v)\n", path, err)
+				continue
+			}
+			fmt.Printf("%s (%d filters)\n", path, len(filters))
+		}
+		return nil
+	},
+}
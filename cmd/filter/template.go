@@ -0,0 +1,276 @@
+package filter
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Parameter types supported by filter templates
+const (
+	ParamString   = "string"
+	ParamEnum     = "enum"
+	ParamDate     = "date"
+	ParamDuration = "duration"
+	ParamIP       = "ip"
+)
+
+// ParamTypes lists the parameter types supported by filter templates
+var ParamTypes = []string{ParamString, ParamEnum, ParamDate, ParamDuration, ParamIP}
+
+// Param declares a parameter of a filter template
+type Param struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Default string   `json:"default,omitempty" yaml:"default,omitempty"`
+	Choices []string `json:"choices,omitempty" yaml:"choices,omitempty"` // Allowed values of an enum
+}
+
+// placeholderPattern matches {{name}} placeholders in a filter template
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// paramNamePattern restricts parameter names to those placeholders can refer to
+var paramNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// durationPattern matches relative durations such as 7d or 12h, optionally written as date math
+var durationPattern = regexp.MustCompile(`^(now-)?[0-9]+[smhdwMy]$`)
+
+// ParseParamSpec parses a parameter declaration of the form
+// name:type[:choice|choice...][=default], for example since:duration=7d or
+// platform:enum:Windows|Linux|Mac=Windows
+func ParseParamSpec(spec string) (Param, error) {
+	var p Param
+	decl, def, hasDefault := strings.Cut(spec, "=")
+	parts := strings.SplitN(decl, ":", 3)
+	if len(parts) < 2 {
+		return p, fmt.Errorf("invalid parameter '%s': expected name:type[:choices][=default]", spec)
+	}
+
+	p.Name, p.Type = parts[0], parts[1]
+	if !paramNamePattern.MatchString(p.Name) {
+		return p, fmt.Errorf("invalid parameter name '%s': use letters, digits and '_'", p.Name)
+	}
+	if !validParamType(p.Type) {
+		return p, fmt.Errorf("invalid type '%s' for parameter '%s' (valid types: %s)", p.Type, p.Name, strings.Join(ParamTypes, ", "))
+	}
+
+	if len(parts) == 3 {
+		if p.Type != ParamEnum {
+			return p, fmt.Errorf("parameter '%s': only enum parameters take choices", p.Name)
+		}
+		p.Choices = strings.Split(parts[2], "|")
+	}
+	if p.Type == ParamEnum && len(p.Choices) == 0 {
+		return p, fmt.Errorf("enum parameter '%s' needs choices, e.g. %s:enum:a|b|c", p.Name, p.Name)
+	}
+
+	if hasDefault {
+		if err := p.Check(def); err != nil {
+			return p, fmt.Errorf("invalid default: %v", err)
+		}
+		p.Default = def
+	}
+	return p, nil
+}
+
+// validParamType reports whether t is a supported parameter type
+func validParamType(t string) bool {
+	for _, pt := range ParamTypes {
+		if pt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the parameter, for example "since (duration, default 7d)"
+func (p Param) String() string {
+	desc := p.Type
+	if p.Type == ParamEnum {
+		desc += " " + strings.Join(p.Choices, "|")
+	}
+	if p.Default != "" {
+		desc += ", default " + p.Default
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, desc)
+}
+
+// Check validates a value against the parameter's type
+func (p Param) Check(value string) error {
+	switch p.Type {
+	case ParamString:
+		if value == "" {
+			return fmt.Errorf("parameter '%s' cannot be empty", p.Name)
+		}
+	case ParamEnum:
+		for _, c := range p.Choices {
+			if c == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("parameter '%s' must be one of %s, got '%s'", p.Name, strings.Join(p.Choices, ", "), value)
+	case ParamDate:
+		if _, err := time.Parse("2006-01-02", value); err == nil {
+			return nil
+		}
+		if _, err := time.Parse(time.RFC3339, value); err == nil {
+			return nil
+		}
+		return fmt.Errorf("parameter '%s' must be a date such as 2024-01-31 or 2024-01-31T12:00:00Z, got '%s'", p.Name, value)
+	case ParamDuration:
+		if !durationPattern.MatchString(value) {
+			return fmt.Errorf("parameter '%s' must be a duration such as 30m, 12h, 7d or 2w, got '%s'", p.Name, value)
+		}
+	case ParamIP:
+		if net.ParseIP(value) != nil {
+			return nil
+		}
+		if _, _, err := net.ParseCIDR(value); err == nil {
+			return nil
+		}
+		return fmt.Errorf("parameter '%s' must be an IP address or CIDR block, got '%s'", p.Name, value)
+	}
+	return nil
+}
+
+// format converts a checked value to the text substituted into the filter.
+// Durations become date math reaching back that far, so 7d becomes now-7d.
+func (p Param) format(value string) string {
+	if p.Type == ParamDuration && !strings.HasPrefix(value, "now-") {
+		value = "now-" + value
+	}
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+// sample returns a valid value for the parameter, used to check a template's
+// syntax before it is saved
+func (p Param) sample() string {
+	if p.Default != "" {
+		return p.Default
+	}
+	switch p.Type {
+	case ParamEnum:
+		return p.Choices[0]
+	case ParamDate:
+		return "2024-01-01"
+	case ParamDuration:
+		return "1d"
+	case ParamIP:
+		return "10.0.0.1"
+	}
+	return "value"
+}
+
+// Placeholders returns the names of the placeholders in a filter expression, in order of first use
+func Placeholders(expr string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderPattern.FindAllStringSubmatch(expr, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// IsTemplate reports whether the filter takes parameters
+func (f Filter) IsTemplate() bool {
+	return len(f.Params) > 0
+}
+
+// checkTemplate checks that the placeholders in a filter match its declared parameters
+func (f Filter) checkTemplate() error {
+	declared := make(map[string]bool)
+	for _, p := range f.Params {
+		if declared[p.Name] {
+			return fmt.Errorf("parameter '%s' is declared more than once", p.Name)
+		}
+		declared[p.Name] = true
+	}
+
+	used := make(map[string]bool)
+	for _, name := range Placeholders(f.Filter) {
+		if !declared[name] {
+			return fmt.Errorf("placeholder '{{%s}}' has no matching --param declaration", name)
+		}
+		used[name] = true
+	}
+	for _, p := range f.Params {
+		if !used[p.Name] {
+			return fmt.Errorf("parameter '%s' is not used in the filter", p.Name)
+		}
+	}
+	return nil
+}
+
+// Render fills in the template's placeholders from values, falling back to
+// parameter defaults. Every value is checked against its parameter's type.
+func (f Filter) Render(values map[string]string) (string, error) {
+	params := make(map[string]Param)
+	for _, p := range f.Params {
+		params[p.Name] = p
+	}
+
+	for name := range values {
+		if _, ok := params[name]; !ok {
+			if !f.IsTemplate() {
+				return "", fmt.Errorf("filter '%s' does not take parameters", f.Name)
+			}
+			return "", fmt.Errorf("filter '%s' has no parameter '%s' (parameters: %s)", f.Name, name, strings.Join(f.paramNames(), ", "))
+		}
+	}
+
+	resolved := make(map[string]string)
+	var missing []string
+	for _, p := range f.Params {
+		value, ok := values[p.Name]
+		if !ok {
+			if p.Default == "" {
+				missing = append(missing, p.Name)
+				continue
+			}
+			value = p.Default
+		}
+		if err := p.Check(value); err != nil {
+			return "", err
+		}
+		resolved[p.Name] = p.format(value)
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("filter '%s' needs a value for %s (use --param NAME=VALUE)", f.Name, strings.Join(missing, ", "))
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(f.Filter, func(m string) string {
+		return resolved[placeholderPattern.FindStringSubmatch(m)[1]]
+	}), nil
+}
+
+// paramNames returns the names of the template's parameters
+func (f Filter) paramNames() []string {
+	names := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// ParseParamValues parses NAME=VALUE pairs given with --param
+func ParseParamValues(pairs []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --param '%s': expected NAME=VALUE", pair)
+		}
+		if _, dup := values[name]; dup {
+			return nil, fmt.Errorf("parameter '%s' given more than once", name)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,139 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// SampleFunc turns a handful of matching IDs into readable labels, such as hostnames
+type SampleFunc func(client *utils.FalconClient, ids []string) ([]string, error)
+
+// samplers holds the registered SampleFuncs by filter type
+var samplers = map[string]SampleFunc{}
+
+// RegisterSampler makes fn the way filter test labels sample results of a filter type
+func RegisterSampler(filterType string, fn SampleFunc) {
+	samplers[filterType] = fn
+}
+
+// filterTypeOf works out the type of the filters used in a --filter-name
+// style expression, when every name used belongs to exactly one common type
+func filterTypeOf(filters []Filter, input string) (string, error) {
+	expr, err := parseNameExpr(input)
+	if err != nil {
+		return "", err
+	}
+
+	var common map[string]bool
+	for _, name := range expr.names() {
+		types := make(map[string]bool)
+		for _, f := range filters {
+			if f.Name == name && (common == nil || common[f.Type]) {
+				types[f.Type] = true
+			}
+		}
+		if len(types) == 0 {
+			return "", fmt.Errorf("filter '%s' not found", name)
+		}
+		common = types
+	}
+
+	if len(common) > 1 {
+		return "", fmt.Errorf("'%s' matches filters of several types, use --type to choose one", input)
+	}
+	for t := range common {
+		return t, nil
+	}
+	return "", fmt.Errorf("filter '%s' not found", input)
+}
+
+// testCmd represents the filter test command
+var testCmd = &cobra.Command{
+	Use:   "test NAME",
+	Short: "Show how many results a saved filter matches",
+	Long: `Run a saved filter against the API without listing every result. The query is made with
+limit=1 to read the total number of matches, then a few matching results are shown as samples.
+
+NAME can also be an expression of saved filter names, as accepted by --filter-name, and
+template parameters are given with --param. Use it before running a bulk action on a filter.`,
+	Example: `  falcon-cli filter test windows-servers
+  falcon-cli filter test "windows-servers AND NOT critical-prod" --sample 10
+  falcon-cli filter test recent-host --param host=web01`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filterType, _ := cmd.Flags().GetString("type")
+		sample, _ := cmd.Flags().GetInt("sample")
+		pairs, _ := cmd.Flags().GetStringArray("param")
+
+		if sample < 0 {
+			return fmt.Errorf("--sample must not be negative")
+		}
+
+		values, err := ParseParamValues(pairs)
+		if err != nil {
+			return err
+		}
+
+		if filterType == "" {
+			filters, err := LoadFilters()
+			if err != nil {
+				return err
+			}
+			if filterType, err = filterTypeOf(filters, args[0]); err != nil {
+				return err
+			}
+		}
+
+		endpoint, ok := QueryEndpoints[filterType]
+		if !ok {
+			return fmt.Errorf("cannot test filters of type '%s' (supported types: hosts, alerts, detections, incidents)", filterType)
+		}
+
+		expr, err := Compose(cmd, filterType, "", []string{args[0]}, values)
+		if err != nil {
+			return err
+		}
+
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		params := map[string]string{"filter": expr}
+		total, meta, err := client.Count(endpoint, params)
+		if err != nil {
+			return fmt.Errorf("error testing filter: %w", err)
+		}
+
+		fmt.Printf("Filter:     %s\n", expr)
+		fmt.Printf("Matches:    %d %s\n", total, filterType)
+		fmt.Printf("Query time: %.3fs\n", meta.QueryTime)
+
+		if sample == 0 || total == 0 {
+			return nil
+		}
+
+		// Fetch a few matching results to show what the filter selects
+		var ids []string
+		_, _, err = client.QueryIDs(endpoint, params, utils.PageOptions{Limit: sample}, func(page []string, _ utils.QueryMeta) error {
+			ids = append(ids, page...)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error getting samples: %w", err)
+		}
+
+		labels := ids
+		if sampler, ok := samplers[filterType]; ok && len(ids) > 0 {
+			if labels, err = sampler(client, ids); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Samples:\n  %s\n", strings.Join(labels, "\n  "))
+		return nil
+	},
+}
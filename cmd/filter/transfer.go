@@ -0,0 +1,186 @@
+package filter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Conflict strategies for filter import
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// exportCmd represents the filter export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export saved filters to a YAML or JSON file",
+	Long: `Export your personal saved filters, optionally only those of one type. The output can be
+imported with 'filter import' or used as a filter library.
+
+The format is taken from --format, or from the extension of --file, and defaults to YAML.`,
+	Example: `  falcon-cli filter export > filters.yaml
+  falcon-cli filter export --type hosts --file team/hosts.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filterType, _ := cmd.Flags().GetString("type")
+		format, _ := cmd.Flags().GetString("format")
+		file, _ := cmd.Flags().GetString("file")
+
+		if format == "" {
+			format = "yaml"
+			if strings.EqualFold(filepath.Ext(file), ".json") {
+				format = "json"
+			}
+		}
+
+		filters, err := readFilters()
+		if err != nil {
+			return err
+		}
+
+		var selected []Filter
+		for _, f := range filters {
+			if filterType == "" || f.Type == filterType {
+				selected = append(selected, f)
+			}
+		}
+
+		data, err := encodeFilterFile(selected, format)
+		if err != nil {
+			return err
+		}
+
+		if file == "" {
+			_, err := cmd.OutOrStdout().Write(data)
+			return err
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return fmt.Errorf("error writing %s: %v", file, err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d filters to %s\n", len(selected), file)
+		return nil
+	},
+}
+
+// importCmd represents the filter import command
+var importCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import filters from a YAML or JSON file",
+	Long: `Import filters from a file written by 'filter export', or '-' to read standard input.
+
+When an imported filter has the same name and type as a saved one, --on-conflict decides
+what happens: skip keeps the saved filter, overwrite replaces it, and rename saves the
+imported filter under a new name such as NAME-2. Imported filters are validated like
+'filter save' unless --no-validate is set.`,
+	Example: `  falcon-cli filter import filters.yaml
+  falcon-cli filter import --on-conflict rename --type hosts team/hosts.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		filterType, _ := cmd.Flags().GetString("type")
+
+		switch onConflict {
+		case ConflictSkip, ConflictOverwrite, ConflictRename:
+		default:
+			return fmt.Errorf("invalid --on-conflict '%s' (valid strategies: skip, overwrite, rename)", onConflict)
+		}
+
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("error reading filters: %v", err)
+		}
+
+		source := args[0]
+		if source == "-" {
+			source = "standard input"
+		}
+
+		imported, err := parseFilterFile(data)
+		if err != nil {
+			return fmt.Errorf("error reading filters: %v", err)
+		}
+
+		// Check every filter before changing anything
+		for _, f := range imported {
+			if filterType != "" && f.Type != filterType {
+				continue
+			}
+			if err := validateFilter(cmd, f); err != nil {
+				return fmt.Errorf("filter '%s' of type '%s' is invalid: %w", f.Name, f.Type, err)
+			}
+		}
+
+		filters, err := readFilters()
+		if err != nil {
+			return err
+		}
+
+		note := "Imported from " + source
+		var added, replaced, renamed, skipped int
+		for _, f := range imported {
+			if filterType != "" && f.Type != filterType {
+				continue
+			}
+
+			if _, err := lookup(filters, f.Name, f.Type); err == nil {
+				switch onConflict {
+				case ConflictSkip:
+					fmt.Printf("Skipped filter '%s' for type '%s' (already exists)\n", f.Name, f.Type)
+					skipped++
+					continue
+				case ConflictOverwrite:
+					i, _ := findPersonal(filters, f.Name, f.Type)
+					filters[i] = record(&filters[i], f, note)
+					fmt.Printf("Overwrote filter '%s' for type '%s'\n", f.Name, f.Type)
+					replaced++
+					continue
+				case ConflictRename:
+					original := f.Name
+					f.Name = uniqueName(filters, f.Name, f.Type)
+					fmt.Printf("Imported filter '%s' for type '%s' as '%s'\n", original, f.Type, f.Name)
+					filters = append(filters, record(nil, f, note))
+					renamed++
+					continue
+				}
+			}
+
+			// New filters start their history with the import, as if saved with 'filter save'
+			filters = append(filters, record(nil, f, note))
+			fmt.Printf("Imported filter '%s' for type '%s'\n", f.Name, f.Type)
+			added++
+		}
+
+		if added+replaced+renamed > 0 {
+			if err := writeFilters(filters); err != nil {
+				return fmt.Errorf("error saving filters: %v", err)
+			}
+		}
+
+		fmt.Printf("Imported %d filters (%d new, %d overwritten, %d renamed, %d skipped)\n",
+			added+replaced+renamed, added, replaced, renamed, skipped)
+		return nil
+	},
+}
+
+// uniqueName returns name with the lowest numeric suffix that is not already
+// used by a filter of the same type
+func uniqueName(filters []Filter, name, filterType string) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if _, err := lookup(filters, candidate, filterType); err != nil {
+			return candidate
+		}
+	}
+}
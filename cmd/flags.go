@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/HARSH16DAWAR/falcon-cli/cmd/filter"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// filterExamples are the example filters shown in the --filter help of each filter type
+var filterExamples = map[string]string{
+	"hosts":     "platform_name:'Windows'",
+	"alerts":    "status:'new'+severity_name:'High'",
+	"incidents": "state:'open'+fine_score:>=80",
+}
+
+// addFilterFlags adds the flags used to select results of a filter type with
+// an FQL filter or saved filters of that type
+func addFilterFlags(cmd *cobra.Command, filterType string) {
+	cmd.Flags().String("filter", "", fmt.Sprintf("Filter %s (e.g., %s)", filterType, filterExamples[filterType]))
+	cmd.Flags().StringArray("filter-name", nil, fmt.Sprintf("Use saved %s filters by name, or an expression such as 'a AND NOT (b OR c)' (repeatable, combined with AND)", filterType))
+	cmd.Flags().StringArray("param", nil, "Set a parameter of a saved filter template as NAME=VALUE (repeatable)")
+	cmd.Flags().Bool("explain", false, "Print the merged filter expression and exit without querying the API")
+	cmd.RegisterFlagCompletionFunc("filter-name", filter.CompleteFilterNames(filterType))
+}
+
+// addSortFlag adds the --sort flag of query commands, which is passed to the API as is
+func addSortFlag(cmd *cobra.Command, example string) {
+	cmd.Flags().String("sort", "", fmt.Sprintf("Sort results by FIELD.asc or FIELD.desc (e.g., %s)", example))
+}
+
+// getQueryParams returns the query parameters for a filter and the --sort flag, if the command has one
+func getQueryParams(cmd *cobra.Command, filterValue string) map[string]string {
+	params := make(map[string]string)
+	if filterValue != "" {
+		params["filter"] = filterValue
+	}
+	if sort, _ := cmd.Flags().GetString("sort"); sort != "" {
+		params["sort"] = sort
+	}
+	return params
+}
+
+// addPageFlags adds the pagination flags shared by query commands
+func addPageFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("limit", utils.DefaultPageSize, "Number of results to request per page")
+	cmd.Flags().Int("offset", 0, "Offset of the first result to return")
+	cmd.Flags().Bool("all", false, "Fetch every page until the result set is exhausted")
+	cmd.Flags().Int("max-results", 0, "Stop after this many results (0 means no cap)")
+}
+
+// getPageOptions reads the pagination flags added by addPageFlags
+func getPageOptions(cmd *cobra.Command) (utils.PageOptions, error) {
+	limit, _ := cmd.Flags().GetInt("limit")
+	offset, _ := cmd.Flags().GetInt("offset")
+	all, _ := cmd.Flags().GetBool("all")
+	maxResults, _ := cmd.Flags().GetInt("max-results")
+
+	opts := utils.PageOptions{
+		Limit:      limit,
+		Offset:     offset,
+		All:        all,
+		MaxResults: maxResults,
+	}
+	return opts, opts.Validate()
+}
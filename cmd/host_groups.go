@@ -0,0 +1,495 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/HARSH16DAWAR/falcon-cli/cmd/filter"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Host group endpoints
+const (
+	hostGroupsQueryEndpoint    = "/devices/queries/host-groups/v1"
+	hostGroupsEntitiesEndpoint = "/devices/entities/host-groups/v1"
+)
+
+// maxHostGroupIDsPerRequest is the maximum number of IDs sent in one host group request
+const maxHostGroupIDsPerRequest = 500
+
+// hostGroupTypes are the host group types accepted by host-groups create
+var hostGroupTypes = []string{"static", "staticByID", "dynamic"}
+
+// HostGroup represents a host group returned by the host group entities API
+type HostGroup struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	GroupType         string `json:"group_type"`
+	AssignmentRule    string `json:"assignment_rule"`
+	CreatedBy         string `json:"created_by"`
+	CreatedTimestamp  string `json:"created_timestamp"`
+	ModifiedBy        string `json:"modified_by"`
+	ModifiedTimestamp string `json:"modified_timestamp"`
+}
+
+// HostGroupsResponse represents the response from the host group entities API
+type HostGroupsResponse struct {
+	Resources []HostGroup `json:"resources"`
+	Errors    []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Meta utils.QueryMeta `json:"meta"`
+}
+
+// getHostGroups resolves host group IDs to their details, batching requests
+func getHostGroups(client *utils.FalconClient, ids []string) ([]HostGroup, error) {
+	var groups []HostGroup
+	for _, chunk := range utils.ChunkIDs(ids, maxHostGroupIDsPerRequest) {
+		resp, err := client.GetEntities(hostGroupsEntitiesEndpoint, chunk)
+		if err != nil {
+			return nil, fmt.Errorf("error getting host group details: %w", err)
+		}
+
+		var result HostGroupsResponse
+		if err := client.ParseResponse(resp, &result); err != nil {
+			return nil, err
+		}
+		groups = append(groups, result.Resources...)
+	}
+	return groups, nil
+}
+
+// getHostGroup returns the details of a single host group
+func getHostGroup(client *utils.FalconClient, id string) (HostGroup, error) {
+	groups, err := getHostGroups(client, []string{id})
+	if err != nil {
+		return HostGroup{}, err
+	}
+	if len(groups) == 0 {
+		return HostGroup{}, fmt.Errorf("host group '%s' not found", id)
+	}
+	return groups[0], nil
+}
+
+// sendHostGroup creates or updates a host group, returning it as saved by the API
+func sendHostGroup(client *utils.FalconClient, method string, group map[string]string) (HostGroup, error) {
+	payload, err := json.Marshal(map[string][]map[string]string{"resources": {group}})
+	if err != nil {
+		return HostGroup{}, fmt.Errorf("error encoding request: %v", err)
+	}
+
+	send := client.Post
+	if method == "PATCH" {
+		send = client.Patch
+	}
+	resp, err := send(hostGroupsEntitiesEndpoint, bytes.NewReader(payload))
+	if err != nil {
+		return HostGroup{}, fmt.Errorf("error saving host group: %w", err)
+	}
+
+	var result HostGroupsResponse
+	if err := client.ParseResponse(resp, &result); err != nil {
+		return HostGroup{}, err
+	}
+	if len(result.Resources) == 0 {
+		return HostGroup{}, fmt.Errorf("error saving host group: the API returned no host group")
+	}
+	return result.Resources[0], nil
+}
+
+// deleteHostGroups deletes one batch of host groups. It returns the error
+// message of each group the API did not delete.
+func deleteHostGroups(client *utils.FalconClient, ids []string) (map[string]string, error) {
+	resp, err := client.Delete(hostGroupsEntitiesEndpoint, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting host groups: %w", err)
+	}
+
+	var result struct {
+		Resources []string `json:"resources"`
+		Errors    []struct {
+			ID      string `json:"id"`
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := client.ParseResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	// Groups missing from the resources were not deleted
+	failures := make(map[string]string)
+	for _, id := range ids {
+		failures[id] = "not deleted by the API"
+	}
+	for _, id := range result.Resources {
+		delete(failures, id)
+	}
+	for _, e := range result.Errors {
+		if _, ok := failures[e.ID]; ok {
+			failures[e.ID] = e.Message
+		}
+	}
+	return failures, nil
+}
+
+// addRuleFlags adds the flags that set a dynamic group's assignment rule
+func addRuleFlags(cmd *cobra.Command) {
+	cmd.Flags().String("rule", "", "Assignment rule of a dynamic group, as an FQL filter on host fields (e.g., platform_name:'Windows')")
+	cmd.Flags().StringArray("rule-name", nil, "Use saved host-groups filters as the assignment rule, or an expression such as 'a AND NOT b' (repeatable, combined with AND)")
+	cmd.Flags().StringArray("param", nil, "Set a parameter of a saved filter template as NAME=VALUE (repeatable)")
+	cmd.RegisterFlagCompletionFunc("rule-name", filter.CompleteFilterNames("host-groups"))
+}
+
+// getAssignmentRule returns the assignment rule selected by --rule, --rule-name
+// and --param, validated against the hosts fields
+func getAssignmentRule(cmd *cobra.Command) (string, error) {
+	rule, _ := cmd.Flags().GetString("rule")
+	nameExprs, _ := cmd.Flags().GetStringArray("rule-name")
+	pairs, _ := cmd.Flags().GetStringArray("param")
+
+	values, err := filter.ParseParamValues(pairs)
+	if err != nil {
+		return "", err
+	}
+	if len(values) > 0 && len(nameExprs) == 0 {
+		return "", fmt.Errorf("--param can only be used with --rule-name")
+	}
+	return filter.Compose(cmd, "host-groups", rule, nameExprs, values)
+}
+
+// ruleChanged reports whether any of the assignment rule flags were given.
+// --param counts too, so getAssignmentRule can reject it without --rule-name.
+func ruleChanged(cmd *cobra.Command) bool {
+	return cmd.Flags().Changed("rule") || cmd.Flags().Changed("rule-name") || cmd.Flags().Changed("param")
+}
+
+// hostGroupColumns are the default columns shown for host groups
+var hostGroupColumns = []output.Column{
+	{Header: "NAME", Field: "name"},
+	{Header: "TYPE", Field: "group_type"},
+	{Header: "ASSIGNMENT RULE", Field: "assignment_rule"},
+	{Header: "DESCRIPTION", Field: "description"},
+	{Header: "MODIFIED", Field: "modified_timestamp"},
+	{Header: "ID", Field: "id"},
+}
+
+// printHostGroups prints host groups with the output flags
+func printHostGroups(cmd *cobra.Command, groups []HostGroup) error {
+	printer, err := output.NewFromFlags(cmd, hostGroupColumns)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if err := printer.Add(g); err != nil {
+			return err
+		}
+	}
+	return printer.Flush()
+}
+
+// hostGroupsCmd represents the host-groups command
+var hostGroupsCmd = &cobra.Command{
+	Use:   "host-groups",
+	Short: "Manage host groups",
+	Long: `List, create, update and delete host groups, and manage the members of static groups.
+
+Static groups have members added and removed by hand. Dynamic groups have an assignment rule, an FQL
+filter on host fields, that decides their members. Assignment rules can be saved with
+'filter save --type host-groups' and used with --rule-name.`,
+}
+
+// hostGroupsListCmd represents the host-groups list command
+var hostGroupsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List host groups",
+	Long: `List host groups with their type, assignment rule and description. You can filter groups on their own
+fields, such as name and group_type, with --filter, and order them with --sort.
+
+Results are paginated with --limit and --offset. Use --all to walk every page.`,
+	Example: `  falcon-cli host-groups list
+  falcon-cli host-groups list --filter "group_type:'dynamic'" --sort name.asc`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pageOpts, err := getPageOptions(cmd)
+		if err != nil {
+			return err
+		}
+		filterValue, _ := cmd.Flags().GetString("filter")
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		printer, err := output.NewFromFlags(cmd, hostGroupColumns)
+		if err != nil {
+			return err
+		}
+
+		// Resolve each page of IDs as it arrives
+		count, meta, err := client.QueryIDs(hostGroupsQueryEndpoint, getQueryParams(cmd, filterValue), pageOpts, func(ids []string, _ utils.QueryMeta) error {
+			groups, err := getHostGroups(client, ids)
+			if err != nil {
+				return err
+			}
+			for _, g := range groups {
+				if err := printer.Add(g); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error getting host groups: %w", err)
+		}
+		if err := printer.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Found %d of %d host groups\n", count, meta.Pagination.Total)
+		return nil
+	},
+}
+
+// hostGroupsGetCmd represents the host-groups get command
+var hostGroupsGetCmd = &cobra.Command{
+	Use:   "get GROUP_ID...",
+	Short: "Show full details for host groups",
+	Long:  `Show the details of host groups. Use -o json or -o yaml to see who created and last changed them.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		groups, err := getHostGroups(client, args)
+		if err != nil {
+			return err
+		}
+		return printHostGroups(cmd, groups)
+	},
+}
+
+// hostGroupsCreateCmd represents the host-groups create command
+var hostGroupsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a host group",
+	Long: `Create a static or dynamic host group. Dynamic groups need an assignment rule, given with --rule or
+with saved host-groups filters through --rule-name. The rule is checked against the hosts fields unless
+--no-validate is set.`,
+	Example: `  falcon-cli host-groups create --name web-servers --description "Production web tier"
+  falcon-cli host-groups create --name windows-servers --type dynamic \
+    --rule "platform_name:'Windows'+product_type_desc:'Server'"
+  falcon-cli host-groups create --name tagged-web --type dynamic --rule-name web-tag`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		groupType, _ := cmd.Flags().GetString("type")
+		description, _ := cmd.Flags().GetString("description")
+
+		rule, err := getAssignmentRule(cmd)
+		if err != nil {
+			return err
+		}
+
+		switch groupType {
+		case "static", "staticByID":
+			if rule != "" {
+				return fmt.Errorf("only dynamic groups have an assignment rule; use --type dynamic")
+			}
+		case "dynamic":
+			if rule == "" {
+				return fmt.Errorf("dynamic groups need an assignment rule; use --rule or --rule-name")
+			}
+		default:
+			return fmt.Errorf("invalid --type '%s' (valid types: static, staticByID, dynamic)", groupType)
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		group := map[string]string{
+			"name":        name,
+			"description": description,
+			"group_type":  groupType,
+		}
+		if rule != "" {
+			group["assignment_rule"] = rule
+		}
+		created, err := sendHostGroup(client, "POST", group)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Created host group '%s'\n", created.Name)
+		return printHostGroups(cmd, []HostGroup{created})
+	},
+}
+
+// hostGroupsUpdateCmd represents the host-groups update command
+var hostGroupsUpdateCmd = &cobra.Command{
+	Use:   "update GROUP_ID",
+	Short: "Change the name, description or assignment rule of a host group",
+	Long: `Change the name, description or assignment rule of a host group. Only the fields given are changed.
+Assignment rules can only be set on dynamic groups, and are checked like those of 'host-groups create'.`,
+	Example: `  falcon-cli host-groups update 1a2b3c --description "Production and staging web tier"
+  falcon-cli host-groups update 4d5e6f --rule "platform_name:'Windows'+tags:'FalconGroupingTags/prod'"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		group := map[string]string{"id": args[0]}
+		if cmd.Flags().Changed("name") {
+			group["name"], _ = cmd.Flags().GetString("name")
+		}
+		if cmd.Flags().Changed("description") {
+			group["description"], _ = cmd.Flags().GetString("description")
+		}
+		if ruleChanged(cmd) {
+			rule, err := getAssignmentRule(cmd)
+			if err != nil {
+				return err
+			}
+			group["assignment_rule"] = rule
+		}
+		if len(group) == 1 {
+			return fmt.Errorf("nothing to update; use --name, --description, --rule or --rule-name")
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		if _, ok := group["assignment_rule"]; ok {
+			existing, err := getHostGroup(client, args[0])
+			if err != nil {
+				return err
+			}
+			if existing.GroupType != "dynamic" {
+				return fmt.Errorf("host group '%s' is a %s group; only dynamic groups have an assignment rule", existing.Name, existing.GroupType)
+			}
+		}
+
+		updated, err := sendHostGroup(client, "PATCH", group)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Updated host group '%s'\n", updated.Name)
+		return printHostGroups(cmd, []HostGroup{updated})
+	},
+}
+
+// hostGroupsDeleteCmd represents the host-groups delete command
+var hostGroupsDeleteCmd = &cobra.Command{
+	Use:   "delete GROUP_ID...",
+	Short: "Delete host groups",
+	Long: `Delete host groups. The hosts in them are not affected, but policies assigned through the groups no
+longer apply to them. The groups are listed and confirmation is asked for; use --yes to skip the question.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		groups, err := getHostGroups(client, args)
+		if err != nil {
+			return err
+		}
+		if len(groups) < len(args) {
+			found := make(map[string]bool, len(groups))
+			for _, g := range groups {
+				found[g.ID] = true
+			}
+			for _, id := range args {
+				if !found[id] {
+					return fmt.Errorf("host group '%s' not found", id)
+				}
+			}
+		}
+
+		if !yes {
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return fmt.Errorf("cannot ask for confirmation without an interactive terminal; use --yes to delete the host groups")
+			}
+			fmt.Printf("About to delete %d host groups:\n", len(groups))
+			for _, g := range groups {
+				fmt.Printf("  %s (%s, %s)\n", g.Name, g.GroupType, g.ID)
+			}
+			proceed := false
+			if err := survey.AskOne(&survey.Confirm{Message: fmt.Sprintf("Delete these %d host groups?", len(groups))}, &proceed); err != nil {
+				return fmt.Errorf("failed to get answer: %v", err)
+			}
+			if !proceed {
+				fmt.Println("Aborted")
+				return nil
+			}
+		}
+
+		failures := make(map[string]string)
+		for _, chunk := range utils.ChunkIDs(args, maxHostGroupIDsPerRequest) {
+			chunkFailures, err := deleteHostGroups(client, chunk)
+			for _, id := range chunk {
+				if err != nil {
+					failures[id] = err.Error()
+				} else if msg, failed := chunkFailures[id]; failed {
+					failures[id] = msg
+				}
+			}
+		}
+
+		for _, g := range groups {
+			if msg, failed := failures[g.ID]; failed {
+				fmt.Fprintf(os.Stderr, "Failed to delete host group '%s' (%s): %s\n", g.Name, g.ID, msg)
+			} else {
+				fmt.Printf("Deleted host group '%s' (%s)\n", g.Name, g.ID)
+			}
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("failed to delete %d of %d host groups", len(failures), len(groups))
+		}
+		return nil
+	},
+}
+
+func init() {
+	hostGroupsListCmd.Flags().String("filter", "", "Filter host groups (e.g., group_type:'dynamic')")
+	addSortFlag(hostGroupsListCmd, "name.asc")
+	addPageFlags(hostGroupsListCmd)
+
+	hostGroupsCreateCmd.Flags().String("name", "", "Name of the host group")
+	hostGroupsCreateCmd.Flags().String("type", "static", "Group type: static, staticByID or dynamic")
+	hostGroupsCreateCmd.Flags().String("description", "", "Description of the host group")
+	addRuleFlags(hostGroupsCreateCmd)
+	hostGroupsCreateCmd.MarkFlagRequired("name")
+	hostGroupsCreateCmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(hostGroupTypes, cobra.ShellCompDirectiveNoFileComp))
+
+	hostGroupsUpdateCmd.Flags().String("name", "", "New name of the host group")
+	hostGroupsUpdateCmd.Flags().String("description", "", "New description of the host group")
+	addRuleFlags(hostGroupsUpdateCmd)
+
+	hostGroupsDeleteCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+
+	hostGroupsCmd.AddCommand(hostGroupsListCmd)
+	hostGroupsCmd.AddCommand(hostGroupsGetCmd)
+	hostGroupsCmd.AddCommand(hostGroupsCreateCmd)
+	hostGroupsCmd.AddCommand(hostGroupsUpdateCmd)
+	hostGroupsCmd.AddCommand(hostGroupsDeleteCmd)
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/fql"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// hostGroupMembersQueryEndpoint is the query endpoint for the host IDs in a host group
+const hostGroupMembersQueryEndpoint = "/devices/queries/host-group-members/v1"
+
+// memberVerbs are the verbs used in messages for each host group action
+var memberVerbs = map[string]string{"add-hosts": "add", "remove-hosts": "remove"}
+
+// changeMembers adds or removes hosts from a static host group in batches,
+// returning the error message of each host that was not changed
+func changeMembers(client *utils.FalconClient, action, groupID string, ids []string) map[string]string {
+	failures := make(map[string]string)
+	for _, chunk := range utils.ChunkIDs(ids, maxHostGroupIDsPerRequest) {
+		chunkFailures, err := postMemberChange(client, action, groupID, chunk)
+		for _, id := range chunk {
+			if err != nil {
+				failures[id] = err.Error()
+			} else if msg, failed := chunkFailures[id]; failed {
+				failures[id] = msg
+			}
+		}
+	}
+	return failures
+}
+
+// postMemberChange sends one batch to the host group actions endpoint. It
+// returns the error message of each host the API did not change. Errors that
+// name no host in the batch apply to all of them.
+func postMemberChange(client *utils.FalconClient, action, groupID string, ids []string) (map[string]string, error) {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = fql.Quote(id)
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"ids": []string{groupID},
+		"action_parameters": []ActionParameter{
+			{Name: "filter", Value: "(device_id:[" + strings.Join(quoted, ",") + "])"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %v", err)
+	}
+
+	resp, err := client.PostWithParams("/devices/entities/host-group-actions/v1", map[string]string{"action_name": action}, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error changing host group members: %w", err)
+	}
+
+	var result struct {
+		Resources []HostGroup `json:"resources"`
+		Errors    []struct {
+			ID      string `json:"id"`
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := client.ParseResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	inBatch := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		inBatch[id] = true
+	}
+	failures := make(map[string]string)
+	for _, e := range result.Errors {
+		if inBatch[e.ID] {
+			failures[e.ID] = e.Message
+			continue
+		}
+		for _, id := range ids {
+			if _, failed := failures[id]; !failed {
+				failures[id] = e.Message
+			}
+		}
+	}
+	// Without the group in the resources, the API did not apply the change
+	if len(result.Resources) == 0 {
+		for _, id := range ids {
+			if _, failed := failures[id]; !failed {
+				failures[id] = "not changed by the API"
+			}
+		}
+	}
+	return failures, nil
+}
+
+// runMemberChange selects hosts and adds them to or removes them from a static host group
+func runMemberChange(cmd *cobra.Command, args []string, action string) error {
+	groupID, hostArgs := args[0], args[1:]
+
+	// Get filter value
+	filterValue, err := getFilterValue(cmd)
+	if err != nil {
+		return err
+	}
+	if len(hostArgs) > 0 && filterValue != "" {
+		return hostIDsWithFilterError(cmd)
+	}
+	if len(hostArgs) == 0 && filterValue == "" {
+		return fmt.Errorf("give host IDs, '-' to read them from standard input, --filter or --filter-name to select hosts")
+	}
+	if explainFilter(cmd, filterValue) {
+		return nil
+	}
+
+	// Create Falcon client
+	client, err := utils.NewFalconClient()
+	if err != nil {
+		return fmt.Errorf("error creating Falcon client: %w", err)
+	}
+
+	group, err := getHostGroup(client, groupID)
+	if err != nil {
+		return err
+	}
+	if group.GroupType == "dynamic" {
+		return fmt.Errorf("host group '%s' is dynamic; its members are set by its assignment rule", group.Name)
+	}
+
+	ids, err := selectHostIDs(cmd, client, hostArgs, hostsQueryEndpoint, filterValue, 0)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		fmt.Println("No hosts selected")
+		return nil
+	}
+
+	failures := changeMembers(client, action, groupID, ids)
+	for _, id := range ids {
+		if msg, failed := failures[id]; failed {
+			fmt.Fprintf(os.Stderr, "Failed to %s host %s: %s\n", memberVerbs[action], id, msg)
+		}
+	}
+
+	changed := len(ids) - len(failures)
+	if action == "add-hosts" {
+		fmt.Printf("Added %d of %d hosts to host group '%s'\n", changed, len(ids), group.Name)
+	} else {
+		fmt.Printf("Removed %d of %d hosts from host group '%s'\n", changed, len(ids), group.Name)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to %s %d of %d hosts", memberVerbs[action], len(failures), len(ids))
+	}
+	return nil
+}
+
+// hostGroupsMembersCmd represents the host-groups members command
+var hostGroupsMembersCmd = &cobra.Command{
+	Use:   "members GROUP_ID",
+	Short: "List the hosts in a host group",
+	Long: `List the hosts in a host group with the same details as 'hosts get'. Members can be narrowed down with
+--filter or --filter-name.
+
+Results are paginated with --limit and --offset. Use --all to walk every page, or --max-results to stop after a given number of hosts.`,
+	Example: `  falcon-cli host-groups members 1a2b3c --all
+  falcon-cli host-groups members 1a2b3c --filter "platform_name:'Linux'" -o csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pageOpts, err := getPageOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Get filter value
+		filterValue, err := getFilterValue(cmd)
+		if err != nil {
+			return err
+		}
+		if explainFilter(cmd, filterValue) {
+			return nil
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		printer, err := output.NewFromFlags(cmd, deviceColumns)
+		if err != nil {
+			return err
+		}
+
+		params := getQueryParams(cmd, filterValue)
+		params["id"] = args[0]
+
+		// Resolve each page of member IDs as it arrives
+		count, meta, err := client.QueryIDs(hostGroupMembersQueryEndpoint, params, pageOpts, func(ids []string, _ utils.QueryMeta) error {
+			devices, err := getDevices(client, ids)
+			if err != nil {
+				return err
+			}
+			for _, d := range devices {
+				if err := printer.Add(d); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error getting host group members: %w", err)
+		}
+		if err := printer.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Found %d of %d members\n", count, meta.Pagination.Total)
+		return nil
+	},
+}
+
+// hostGroupsAddHostsCmd represents the host-groups add-hosts command
+var hostGroupsAddHostsCmd = &cobra.Command{
+	Use:   "add-hosts GROUP_ID [HOST_ID...|-]",
+	Short: "Add hosts to a static host group",
+	Long: `Add hosts to a static host group. Hosts are given as IDs, as '-' to read IDs from standard input, or
+with --filter or --filter-name.`,
+	Example: `  falcon-cli host-groups add-hosts 1a2b3c 4d5e6f 7a8b9c
+  falcon-cli host-groups add-hosts 1a2b3c --filter-name web-servers`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMemberChange(cmd, args, "add-hosts")
+	},
+}
+
+// hostGroupsRemoveHostsCmd represents the host-groups remove-hosts command
+var hostGroupsRemoveHostsCmd = &cobra.Command{
+	Use:   "remove-hosts GROUP_ID [HOST_ID...|-]",
+	Short: "Remove hosts from a static host group",
+	Long: `Remove hosts from a static host group. Hosts are given as IDs, as '-' to read IDs from standard input,
+or with --filter or --filter-name.`,
+	Example: `  falcon-cli host-groups remove-hosts 1a2b3c 4d5e6f
+  falcon-cli host-groups members 1a2b3c --stale-days 30 --columns device_id --no-headers | \
+    falcon-cli host-groups remove-hosts 1a2b3c -`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMemberChange(cmd, args, "remove-hosts")
+	},
+}
+
+func init() {
+	addFilterFlags(hostGroupsMembersCmd, "hosts")
+	addStaleDaysFlag(hostGroupsMembersCmd)
+	addSortFlag(hostGroupsMembersCmd, "hostname.asc")
+	addPageFlags(hostGroupsMembersCmd)
+	addFilterFlags(hostGroupsAddHostsCmd, "hosts")
+	addFilterFlags(hostGroupsRemoveHostsCmd, "hosts")
+
+	hostGroupsCmd.AddCommand(hostGroupsMembersCmd)
+	hostGroupsCmd.AddCommand(hostGroupsAddHostsCmd)
+	hostGroupsCmd.AddCommand(hostGroupsRemoveHostsCmd)
+}
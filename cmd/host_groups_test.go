@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestHostGroupsUpdateRuleFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []string
+		wantErr string
+	}{
+		{name: "param without rule-name", flags: []string{"--param", "os=Windows"}, wantErr: "--param can only be used with --rule-name"},
+		{name: "param with rule", flags: []string{"--rule", "platform_name:'Windows'", "--param", "os=Windows"}, wantErr: "--param can only be used with --rule-name"},
+		{name: "nothing to update", flags: nil, wantErr: "nothing to update"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			})
+			cmd, _ := newTestCommand(func(cmd *cobra.Command) {
+				cmd.Flags().String("name", "", "")
+				cmd.Flags().String("description", "", "")
+				addRuleFlags(cmd)
+			})
+			if err := cmd.ParseFlags(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+
+			err := hostGroupsUpdateCmd.RunE(cmd, []string{"group-1"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("update error = %v, want %q", err, tt.wantErr)
+			}
+			if len(*calls) != 0 {
+				t.Errorf("made %d API calls", len(*calls))
+			}
+		})
+	}
+}
+
+func TestDeleteHostGroups(t *testing.T) {
+	// g1 is deleted, g2 is rejected with a message and g3 is left out of the response
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resources":["g1"],"errors":[{"id":"g2","code":409,"message":"group is in use by a policy"}]}`)
+	})
+
+	failures, err := deleteHostGroups(client, []string{"g1", "g2", "g3"})
+	if err != nil {
+		t.Fatalf("deleteHostGroups returned error: %v", err)
+	}
+	want := map[string]string{"g2": "group is in use by a policy", "g3": "not deleted by the API"}
+	if !reflect.DeepEqual(failures, want) {
+		t.Errorf("failures = %v, want %v", failures, want)
+	}
+	if call := (*calls)[0]; call.Method != "DELETE" || call.Query != "ids=g1&ids=g2&ids=g3" {
+		t.Errorf("request = %s %s?%s", call.Method, call.Path, call.Query)
+	}
+}
+
+func TestHostGroupsDeletePartialFailure(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"resources":[{"id":"g1","name":"web"},{"id":"g2","name":"db"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"resources":["g1"],"errors":[{"id":"g2","code":409,"message":"group is in use by a policy"}]}`)
+	})
+	cmd, _ := newTestCommand(func(cmd *cobra.Command) { cmd.Flags().BoolP("yes", "y", false, "") })
+	if err := cmd.ParseFlags([]string{"--yes"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := hostGroupsDeleteCmd.RunE(cmd, []string{"g1", "g2"})
+	if err == nil || err.Error() != "failed to delete 1 of 2 host groups" {
+		t.Errorf("delete error = %v, want a failure for 1 of 2 host groups", err)
+	}
+}
+
+func TestChangeMembers(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     map[string]string
+	}{
+		{
+			name:     "all changed",
+			response: `{"resources":[{"id":"g1"}]}`,
+			want:     map[string]string{},
+		},
+		{
+			name:     "host errors",
+			response: `{"resources":[{"id":"g1"}],"errors":[{"id":"h2","code":404,"message":"host not found"}]}`,
+			want:     map[string]string{"h2": "host not found"},
+		},
+		{
+			name:     "error for the whole batch",
+			response: `{"resources":[],"errors":[{"code":400,"message":"group is not static"}]}`,
+			want:     map[string]string{"h1": "group is not static", "h2": "group is not static", "h3": "group is not static"},
+		},
+		{
+			name:     "group missing from the response",
+			response: `{"resources":[]}`,
+			want:     map[string]string{"h1": "not changed by the API", "h2": "not changed by the API", "h3": "not changed by the API"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.response)
+			})
+
+			failures := changeMembers(client, "add-hosts", "g1", []string{"h1", "h2", "h3"})
+			if !reflect.DeepEqual(failures, tt.want) {
+				t.Errorf("failures = %v, want %v", failures, tt.want)
+			}
+
+			call := (*calls)[0]
+			if call.Query != "action_name=add-hosts" {
+				t.Errorf("query = %s", call.Query)
+			}
+			wantBody := `{"action_parameters":[{"name":"filter","value":"(device_id:['h1','h2','h3'])"}],"ids":["g1"]}`
+			if call.Body != wantBody {
+				t.Errorf("request body = %s, want %s", call.Body, wantBody)
+			}
+		})
+	}
+}
+
+func TestChangeMembersBatchError(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors":[{"code":403,"message":"access denied"}]}`)
+	})
+
+	failures := changeMembers(client, "remove-hosts", "g1", []string{"h1", "h2"})
+	if len(failures) != 2 || !strings.Contains(failures["h1"], "access denied") {
+		t.Errorf("failures = %v, want both hosts failed with the API error", failures)
+	}
+}
+
+func TestRunMemberChangePartialFailure(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"resources":[{"id":"g1","name":"web","group_type":"static"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"resources":[{"id":"g1"}],"errors":[{"id":"h2","code":404,"message":"host not found"}]}`)
+	})
+	cmd, _ := newTestCommand(func(cmd *cobra.Command) { addFilterFlags(cmd, "hosts") })
+
+	err := runMemberChange(cmd, []string{"g1", "h1", "h2"}, "add-hosts")
+	if err == nil || err.Error() != "failed to add 1 of 2 hosts" {
+		t.Errorf("runMemberChange error = %v, want a failure for 1 of 2 hosts", err)
+	}
+}
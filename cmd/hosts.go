@@ -1,109 +1,201 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
+	"os"
+	"strings"
 
 	"github.com/HARSH16DAWAR/falcon-cli/cmd/filter"
 	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/fql"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
-// HostsResponse represents the response from the hosts API
-type HostsResponse struct {
-	Resources []string `json:"resources"`
-	Errors    []struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-	} `json:"errors"`
-	Meta struct {
-		QueryTime float64 `json:"query_time"`
-		PoweredBy string  `json:"powered_by"`
-		TraceID   string  `json:"trace_id"`
-	} `json:"meta"`
-}
+// Query endpoints for visible and hidden host IDs
+const (
+	hostsQueryEndpoint       = "/devices/queries/devices/v1"
+	hiddenHostsQueryEndpoint = "/devices/queries/devices-hidden/v2"
+)
 
-// getFilterValue returns the filter value, either from the --filter flag or from a saved filter
+// getFilterValue returns the hosts filter selected by --filter, --filter-name and --param,
+// narrowed to hosts not seen for --stale-days days on commands that have that flag
 func getFilterValue(cmd *cobra.Command) (string, error) {
-	filterValue, _ := cmd.Flags().GetString("filter")
-	filterName, _ := cmd.Flags().GetString("filter-name")
+	filterValue, err := filter.FromFlags(cmd, "hosts")
+	if err != nil {
+		return "", err
+	}
 
-	if filterValue != "" && filterName != "" {
-		return "", fmt.Errorf("cannot use both --filter and --filter-name")
+	staleDays, _ := cmd.Flags().GetInt("stale-days")
+	if staleDays < 0 {
+		return "", fmt.Errorf("--stale-days must not be negative")
+	}
+	if staleDays == 0 {
+		return filterValue, nil
 	}
 
-	if filterName != "" {
-		var filters []filter.Filter
-		if err := viper.UnmarshalKey("filters", &filters); err != nil {
-			return "", fmt.Errorf("error reading filters: %v", err)
-		}
+	stale := fmt.Sprintf("last_seen:<='now-%dd'", staleDays)
+	if filterValue == "" {
+		return stale, nil
+	}
+	node, err := fql.Parse(filterValue)
+	if err != nil {
+		return "", fmt.Errorf("filter cannot be combined with --stale-days: %w", err)
+	}
+	staleNode, err := fql.Parse(stale)
+	if err != nil {
+		return "", err
+	}
+	return fql.AndOf(node, staleNode).String(), nil
+}
 
-		for _, f := range filters {
-			if f.Name == filterName && f.Type == "hosts" {
-				return f.Filter, nil
-			}
+// addStaleDaysFlag adds the --stale-days flag read by getFilterValue
+func addStaleDaysFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("stale-days", 0, "Only select hosts last seen at least this many days ago")
+}
+
+// hostIDsWithFilterError reports that host IDs were given together with flags
+// that select hosts by filter, naming the flags that were set
+func hostIDsWithFilterError(cmd *cobra.Command) error {
+	var set []string
+	for _, name := range []string{"filter", "filter-name", "stale-days"} {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+			set = append(set, "--"+name)
 		}
-		return "", fmt.Errorf("filter '%s' not found for type 'hosts'", filterName)
 	}
+	if len(set) == 0 {
+		set = []string{"--filter", "--filter-name"}
+	}
+	return fmt.Errorf("cannot use host IDs together with %s", strings.Join(set, " or "))
+}
+
+// hostsEndpoint returns the query endpoint for hidden hosts when --hidden is set
+func hostsEndpoint(cmd *cobra.Command) string {
+	if hidden, _ := cmd.Flags().GetBool("hidden"); hidden {
+		return hiddenHostsQueryEndpoint
+	}
+	return hostsQueryEndpoint
+}
+
+// explainFilter prints the merged filter when --explain is set, reporting
+// whether it did so and the command should stop there
+func explainFilter(cmd *cobra.Command, filterValue string) bool {
+	explain, _ := cmd.Flags().GetBool("explain")
+	if !explain {
+		return false
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), filterValue)
+	return true
+}
 
-	return filterValue, nil
+// hostIDColumns are the columns shown when listing host IDs
+var hostIDColumns = []output.Column{
+	{Header: "DEVICE ID"},
 }
 
 // hostsCmd represents the hosts command
 var hostsCmd = &cobra.Command{
 	Use:   "hosts",
 	Short: "List hosts in your Falcon environment",
-	Long:  `List all hosts in your Falcon environment with their details. You can filter hosts using the --filter flag or a saved filter using --filter-name.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get filter value
-		filterValue, err := getFilterValue(cmd)
-		if err != nil {
-			return err
-		}
+	Long: `List all hosts in your Falcon environment with their details. You can filter hosts using the --filter flag or a saved filter using --filter-name.
 
-		// Create Falcon client
-		client, err := utils.NewFalconClient()
-		if err != nil {
-			return fmt.Errorf("error creating Falcon client: %v", err)
-		}
+Results are paginated with --limit and --offset. Use --all to walk every page, or --max-results to stop after a given number of hosts.
+Use -o ndjson or -o csv to stream IDs as each page arrives. Use --count-only to print just the number of matching hosts.
 
-		// Prepare query parameters
-		params := make(map[string]string)
-		if filterValue != "" {
-			params["filter"] = filterValue
-		}
+Use --hidden to list hidden hosts instead, and --stale-days to only list hosts that have not been seen for a number of days.
+'hosts list' is the same command.`,
+	RunE: runHostsList,
+}
 
-		// Make request to get hosts
-		resp, err := client.Get("/devices/queries/devices/v1", params)
-		if err != nil {
-			return fmt.Errorf("error getting hosts: %v", err)
-		}
-		defer resp.Body.Close()
+// hostsListCmd represents the hosts list command, which lists hosts like hosts on its own
+var hostsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List host IDs",
+	Long: `List host IDs, the same as 'hosts' on its own. It takes the same filter, pagination, --hidden, --stale-days
+and --count-only flags.`,
+	Example: `  falcon-cli hosts list --hidden
+  falcon-cli hosts list --stale-days 30 --count-only`,
+	Args: cobra.NoArgs,
+	RunE: runHostsList,
+}
 
-		// Read the raw response body
-		body, err := io.ReadAll(resp.Body)
+// runHostsList lists the IDs of the hosts selected by the hosts and hosts list flags
+func runHostsList(cmd *cobra.Command, args []string) error {
+	// Get filter value
+	filterValue, err := getFilterValue(cmd)
+	if err != nil {
+		return err
+	}
+	if explainFilter(cmd, filterValue) {
+		return nil
+	}
+
+	// Get pagination options
+	pageOpts, err := getPageOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Create Falcon client
+	client, err := utils.NewFalconClient()
+	if err != nil {
+		return fmt.Errorf("error creating Falcon client: %w", err)
+	}
+
+	// Prepare query parameters
+	params := make(map[string]string)
+	if filterValue != "" {
+		params["filter"] = filterValue
+	}
+
+	// Only read the total when just the count is wanted
+	if countOnly, _ := cmd.Flags().GetBool("count-only"); countOnly {
+		total, meta, err := client.Count(hostsEndpoint(cmd), params)
 		if err != nil {
-			return fmt.Errorf("error reading response body: %v", err)
+			return fmt.Errorf("error counting hosts: %w", err)
 		}
+		fmt.Fprintln(cmd.OutOrStdout(), total)
+		fmt.Fprintf(os.Stderr, "Query time: %.3fs\n", meta.QueryTime)
+		return nil
+	}
 
-		// Pretty print the raw JSON
-		var prettyJSON bytes.Buffer
-		if err := json.Indent(&prettyJSON, body, "", "  "); err != nil {
-			return fmt.Errorf("error formatting JSON: %v", err)
+	printer, err := output.NewFromFlags(cmd, hostIDColumns)
+	if err != nil {
+		return err
+	}
+
+	// Stream host IDs as each page arrives
+	count, meta, err := client.QueryIDs(hostsEndpoint(cmd), params, pageOpts, func(ids []string, _ utils.QueryMeta) error {
+		for _, id := range ids {
+			if err := printer.Add(id); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error getting hosts: %w", err)
+	}
+	if err := printer.Flush(); err != nil {
+		return err
+	}
 
-		// Print the raw JSON response
-		fmt.Println(prettyJSON.String())
+	fmt.Fprintf(os.Stderr, "Found %d of %d hosts\n", count, meta.Pagination.Total)
 
-		return nil
-	},
+	return nil
+}
+
+// addHostsListFlags adds the flags of hosts and hosts list
+func addHostsListFlags(cmd *cobra.Command) {
+	addFilterFlags(cmd, "hosts")
+	addStaleDaysFlag(cmd)
+	cmd.Flags().Bool("hidden", false, "List hidden hosts instead of visible ones")
+	cmd.Flags().Bool("count-only", false, "Print the number of matching hosts instead of their IDs")
+	addPageFlags(cmd)
 }
 
 func init() {
-	hostsCmd.Flags().String("filter", "", "Filter hosts (e.g., platform_name:'Windows')")
-	hostsCmd.Flags().String("filter-name", "", "Use a saved filter by name")
-	RootCmd.AddCommand(hostsCmd)
+	addHostsListFlags(hostsCmd)
+	addHostsListFlags(hostsListCmd)
+	hostsCmd.AddCommand(hostsListCmd)
 }
@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// maxDeviceIDsPerAction is the maximum number of IDs accepted by the device actions endpoint
+const maxDeviceIDsPerAction = 100
+
+// deviceAction describes an action of the device actions endpoint
+type deviceAction struct {
+	Name   string // action_name sent to the API
+	Verb   string // Used in prompts and errors, e.g. "contain"
+	Done   string // Used in the summary, e.g. "Contained"
+	Hidden bool   // Acts on hidden hosts, so filters select from the hidden hosts
+}
+
+// ActionResult is the outcome of a device action or tag change for one host
+type ActionResult struct {
+	DeviceID string `json:"device_id"`
+	Hostname string `json:"hostname"`
+	Result   string `json:"result"` // "ok" or "failed"; tag changes report "changed" or "unchanged" instead of "ok"
+	Error    string `json:"error,omitempty"`
+}
+
+// actionResultColumns are the columns shown for the outcome of a device action
+var actionResultColumns = []output.Column{
+	{Header: "HOSTNAME", Field: "hostname"},
+	{Header: "DEVICE ID", Field: "device_id"},
+	{Header: "RESULT", Field: "result"},
+	{Header: "ERROR", Field: "error"},
+}
+
+// readIDs reads IDs from r, one per line. Blank lines are skipped, and the
+// quotes of JSON strings, as written by -o ndjson, are removed.
+func readIDs(r io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		id := strings.Trim(strings.TrimSpace(scanner.Text()), `"`)
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading IDs: %v", err)
+	}
+	return ids, nil
+}
+
+// selectHostIDs returns the host IDs given as arguments, read from standard
+// input when the only argument is "-", or matching the filter at the query
+// endpoint. At most limit IDs are read from a filter; pass limit+1 to tell
+// whether more hosts match.
+func selectHostIDs(cmd *cobra.Command, client *utils.FalconClient, args []string, endpoint, filterValue string, limit int) ([]string, error) {
+	var ids []string
+	switch {
+	case len(args) == 1 && args[0] == "-":
+		stdinIDs, err := readIDs(cmd.InOrStdin())
+		if err != nil {
+			return nil, err
+		}
+		ids = stdinIDs
+	case len(args) > 0:
+		ids = args
+	default:
+		_, _, err := client.QueryIDs(endpoint, map[string]string{"filter": filterValue}, utils.PageOptions{All: true, MaxResults: limit}, func(page []string, _ utils.QueryMeta) error {
+			ids = append(ids, page...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error getting hosts: %w", err)
+		}
+	}
+
+	// Drop duplicates, keeping the order the IDs were given in
+	seen := make(map[string]bool, len(ids))
+	var unique []string
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+// confirmAction lists the hosts an action will affect and asks whether to go
+// ahead, unless --yes is set. It fails when there is no terminal to ask on.
+func confirmAction(cmd *cobra.Command, action deviceAction, ids []string, hostnames map[string]string) (bool, error) {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("cannot ask for confirmation without an interactive terminal; use --yes to %s the hosts", action.Verb)
+	}
+
+	fmt.Printf("About to %s %d hosts:\n", action.Verb, len(ids))
+	for _, id := range ids {
+		fmt.Printf("  %s (%s)\n", hostnameOrUnknown(hostnames, id), id)
+	}
+
+	proceed := false
+	prompt := &survey.Confirm{Message: fmt.Sprintf("%s these %d hosts?", strings.ToUpper(action.Verb[:1])+action.Verb[1:], len(ids))}
+	if err := survey.AskOne(prompt, &proceed); err != nil {
+		return false, fmt.Errorf("failed to get answer: %v", err)
+	}
+	return proceed, nil
+}
+
+// hostnameOrUnknown returns the hostname of a host, or a placeholder for hosts that were not found
+func hostnameOrUnknown(hostnames map[string]string, id string) string {
+	if hostname, ok := hostnames[id]; ok && hostname != "" {
+		return hostname
+	}
+	return "(unknown)"
+}
+
+// performDeviceAction runs an action on hosts in batches the endpoint accepts,
+// reporting the outcome for each host
+func performDeviceAction(client *utils.FalconClient, action deviceAction, ids []string, hostnames map[string]string) []ActionResult {
+	var results []ActionResult
+	for _, chunk := range utils.ChunkIDs(ids, maxDeviceIDsPerAction) {
+		failures, err := postDeviceAction(client, action, chunk)
+		for _, id := range chunk {
+			result := ActionResult{DeviceID: id, Hostname: hostnameOrUnknown(hostnames, id), Result: "ok"}
+			if err != nil {
+				result.Result, result.Error = "failed", err.Error()
+			} else if msg, failed := failures[id]; failed {
+				result.Result, result.Error = "failed", msg
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// postDeviceAction sends one batch to the device actions endpoint. It returns
+// the error message of each host the API did not act on.
+func postDeviceAction(client *utils.FalconClient, action deviceAction, ids []string) (map[string]string, error) {
+	payload, err := json.Marshal(map[string][]string{"ids": ids})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %v", err)
+	}
+
+	resp, err := client.PostWithParams("/devices/entities/devices-actions/v2", map[string]string{"action_name": action.Name}, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Resources []struct {
+			ID string `json:"id"`
+		} `json:"resources"`
+		Errors []struct {
+			ID      string `json:"id"`
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := client.ParseResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	// Hosts missing from the resources were not acted on
+	failures := make(map[string]string)
+	for _, id := range ids {
+		failures[id] = "not acted on by the API"
+	}
+	for _, r := range result.Resources {
+		delete(failures, r.ID)
+	}
+	for _, e := range result.Errors {
+		if _, ok := failures[e.ID]; ok {
+			failures[e.ID] = e.Message
+		}
+	}
+	return failures, nil
+}
+
+// runDeviceAction selects hosts, checks them against --max-hosts, asks for
+// confirmation and then runs the action, printing the outcome for each host
+func runDeviceAction(cmd *cobra.Command, args []string, action deviceAction) error {
+	maxHosts, _ := cmd.Flags().GetInt("max-hosts")
+
+	// Get filter value
+	filterValue, err := getFilterValue(cmd)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && filterValue != "" {
+		return hostIDsWithFilterError(cmd)
+	}
+	if len(args) == 0 && filterValue == "" {
+		return fmt.Errorf("give host IDs, '-' to read them from standard input, --filter or --filter-name to select the hosts to %s", action.Verb)
+	}
+	if explainFilter(cmd, filterValue) {
+		return nil
+	}
+	if maxHosts <= 0 {
+		return fmt.Errorf("--max-hosts must be positive")
+	}
+
+	// Create Falcon client
+	client, err := utils.NewFalconClient()
+	if err != nil {
+		return fmt.Errorf("error creating Falcon client: %w", err)
+	}
+
+	endpoint := hostsQueryEndpoint
+	if action.Hidden {
+		endpoint = hiddenHostsQueryEndpoint
+	}
+	ids, err := selectHostIDs(cmd, client, args, endpoint, filterValue, maxHosts+1)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		fmt.Printf("No hosts to %s\n", action.Verb)
+		return nil
+	}
+	if len(ids) > maxHosts {
+		return fmt.Errorf("refusing to %s more than %d hosts; raise --max-hosts to %s them all", action.Verb, maxHosts, action.Verb)
+	}
+
+	// Resolve hostnames for the confirmation and the results
+	devices, err := getDevices(client, ids)
+	if err != nil {
+		return err
+	}
+	hostnames := make(map[string]string, len(devices))
+	for _, d := range devices {
+		hostnames[d.DeviceID] = d.Hostname
+	}
+
+	proceed, err := confirmAction(cmd, action, ids, hostnames)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Println("Aborted")
+		return nil
+	}
+
+	results := performDeviceAction(client, action, ids, hostnames)
+
+	printer, err := output.NewFromFlags(cmd, actionResultColumns)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Result != "ok" {
+			failed++
+		}
+		if err := printer.Add(r); err != nil {
+			return err
+		}
+	}
+	if err := printer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %d of %d hosts\n", action.Done, len(results)-failed, len(results))
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d of %d hosts", action.Verb, failed, len(results))
+	}
+	return nil
+}
+
+// addDeviceActionFlags adds the host selection and safeguard flags of device actions
+func addDeviceActionFlags(cmd *cobra.Command, defaultMaxHosts int) {
+	addFilterFlags(cmd, "hosts")
+	cmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	cmd.Flags().Int("max-hosts", defaultMaxHosts, "Refuse to act on more than this many hosts")
+}
+
+// hostsContainCmd represents the hosts contain command
+var hostsContainCmd = &cobra.Command{
+	Use:   "contain [HOST_ID...|-]",
+	Short: "Network contain hosts",
+	Long: `Network contain hosts, cutting them off from the network except for the Falcon cloud.
+
+Hosts are given as IDs, as '-' to read IDs from standard input one per line, or with --filter or --filter-name.
+The hostnames of the affected hosts are shown and confirmation is asked for; use --yes to skip the question in
+scripts. More than --max-hosts hosts are never contained at once. The result is reported for each host, and the
+command fails if any host could not be contained.`,
+	Example: `  falcon-cli hosts contain 1a2b3c4d5e6f
+  falcon-cli hosts contain --filter-name compromised-web --max-hosts 25
+  falcon-cli hosts --filter "hostname:'web-*'" --no-headers | falcon-cli hosts contain - --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeviceAction(cmd, args, deviceAction{Name: "contain", Verb: "contain", Done: "Contained"})
+	},
+}
+
+// hostsLiftContainmentCmd represents the hosts lift-containment command
+var hostsLiftContainmentCmd = &cobra.Command{
+	Use:   "lift-containment [HOST_ID...|-]",
+	Short: "Lift network containment from hosts",
+	Long: `Lift network containment from hosts, restoring their network access.
+
+Hosts are selected, confirmed and reported on the same way as for 'hosts contain'.`,
+	Example: `  falcon-cli hosts lift-containment 1a2b3c4d5e6f
+  falcon-cli hosts lift-containment --filter "status:'contained'" --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeviceAction(cmd, args, deviceAction{Name: "lift_containment", Verb: "lift containment from", Done: "Lifted containment from"})
+	},
+}
+
+// hostsHideCmd represents the hosts hide command
+var hostsHideCmd = &cobra.Command{
+	Use:   "hide [HOST_ID...|-]",
+	Short: "Hide hosts from the host list",
+	Long: `Hide hosts, such as decommissioned or stale VMs, from the host list and from most Falcon queries.
+Hidden hosts can be listed with 'hosts list --hidden' and brought back with 'hosts unhide'.
+
+Hosts are selected, confirmed and reported on the same way as for 'hosts contain'. Use --stale-days N to
+select hosts that have not been seen for N days, on its own or together with a filter.`,
+	Example: `  falcon-cli hosts hide 1a2b3c4d5e6f
+  falcon-cli hosts hide --stale-days 45 --filter "product_type_desc:'Server'"
+  falcon-cli hosts hide --stale-days 90 --max-hosts 1000 --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeviceAction(cmd, args, deviceAction{Name: "hide_host", Verb: "hide", Done: "Hid"})
+	},
+}
+
+// hostsUnhideCmd represents the hosts unhide command
+var hostsUnhideCmd = &cobra.Command{
+	Use:   "unhide [HOST_ID...|-]",
+	Short: "Restore hidden hosts to the host list",
+	Long: `Restore hidden hosts to the host list. Filters select from the hidden hosts, as listed by 'hosts list --hidden'.
+
+Hosts are selected, confirmed and reported on the same way as for 'hosts contain'.`,
+	Example: `  falcon-cli hosts unhide 1a2b3c4d5e6f
+  falcon-cli hosts unhide --filter "hostname:'build-*'"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeviceAction(cmd, args, deviceAction{Name: "unhide_host", Verb: "unhide", Done: "Unhid", Hidden: true})
+	},
+}
+
+func init() {
+	addDeviceActionFlags(hostsContainCmd, 10)
+	addDeviceActionFlags(hostsLiftContainmentCmd, 10)
+	addDeviceActionFlags(hostsHideCmd, 100)
+	addStaleDaysFlag(hostsHideCmd)
+	addDeviceActionFlags(hostsUnhideCmd, 100)
+	hostsCmd.AddCommand(hostsContainCmd)
+	hostsCmd.AddCommand(hostsLiftContainmentCmd)
+	hostsCmd.AddCommand(hostsHideCmd)
+	hostsCmd.AddCommand(hostsUnhideCmd)
+}
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// apiCall is a request received by the test API server
+type apiCall struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// newTestAPI starts a server for handler and points a test profile at it, so
+// commands that create their own client talk to the server. Token requests are
+// answered by the server itself. It returns the calls the handler received.
+func newTestAPI(t *testing.T, handler http.HandlerFunc) *[]apiCall {
+	t.Helper()
+	var calls []apiCall
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			fmt.Fprint(w, `{"access_token":"test-token","expires_in":1800}`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		calls = append(calls, apiCall{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: string(body)})
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("HOME", t.TempDir())
+	utils.RegionBaseURL["test"] = srv.URL
+	viper.Set("falcon.client_id", "test-id")
+	viper.Set("falcon.client_secret", "test-secret")
+	viper.Set("falcon.cloud_region", "test")
+	viper.Set("falcon.max_retries", 0)
+	t.Cleanup(func() {
+		delete(utils.RegionBaseURL, "test")
+		viper.Reset()
+	})
+	return &calls
+}
+
+// newTestClient returns a client for the test API
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*utils.FalconClient, *[]apiCall) {
+	t.Helper()
+	calls := newTestAPI(t, handler)
+	client, err := utils.NewFalconClient()
+	if err != nil {
+		t.Fatalf("NewFalconClient returned error: %v", err)
+	}
+	return client, calls
+}
+
+// newTestCommand returns a command with the output flags, whose output is
+// written to the returned buffer
+func newTestCommand(addFlags func(cmd *cobra.Command)) (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{Use: "test"}
+	output.AddFlags(cmd)
+	addFlags(cmd)
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	return cmd, out
+}
+
+// devicesHandler answers host lookups with a hostname for each ID
+func devicesHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	var devices []Device
+	for _, id := range req.IDs {
+		devices = append(devices, Device{DeviceID: id, Hostname: "host-" + id})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"resources": devices})
+}
+
+func TestReadIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "one per line", input: "a\nb\nc\n", want: []string{"a", "b", "c"}},
+		{name: "blank lines and spaces", input: "\n  a  \n\n\tb\n", want: []string{"a", "b"}},
+		{name: "ndjson strings", input: "\"a\"\n\"b\"\n", want: []string{"a", "b"}},
+		{name: "windows line endings", input: "a\r\nb\r\n", want: []string{"a", "b"}},
+		{name: "empty", input: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readIDs(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("readIDs returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readIDs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectHostIDs(t *testing.T) {
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resources":["f1","f2","f1"],"meta":{"pagination":{"offset":0,"limit":100,"total":3}}}`)
+	})
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		filter string
+		want   []string
+		calls  int
+	}{
+		{name: "arguments", args: []string{"a", "b", "a", "c", "b"}, want: []string{"a", "b", "c"}},
+		{name: "standard input", args: []string{"-"}, stdin: "a\n\"b\"\na\n", want: []string{"a", "b"}},
+		{name: "filter", filter: "hostname:'web-*'", want: []string{"f1", "f2"}, calls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*calls = nil
+			cmd := &cobra.Command{}
+			cmd.SetIn(strings.NewReader(tt.stdin))
+
+			got, err := selectHostIDs(cmd, client, tt.args, hiddenHostsQueryEndpoint, tt.filter, 0)
+			if err != nil {
+				t.Fatalf("selectHostIDs returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectHostIDs = %q, want %q", got, tt.want)
+			}
+			if len(*calls) != tt.calls {
+				t.Errorf("made %d API calls, want %d", len(*calls), tt.calls)
+			}
+			if tt.calls > 0 && !strings.Contains((*calls)[0].Query, "filter=hostname") {
+				t.Errorf("query %q does not send the filter", (*calls)[0].Query)
+			}
+		})
+	}
+}
+
+func TestPostDeviceAction(t *testing.T) {
+	// d1 is acted on, d2 is rejected with a message and d3 is left out of the response
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resources":[{"id":"d1"}],"errors":[{"id":"d2","code":409,"message":"host is offline"},{"id":"other","code":404,"message":"not requested"}]}`)
+	})
+
+	failures, err := postDeviceAction(client, deviceAction{Name: "contain"}, []string{"d1", "d2", "d3"})
+	if err != nil {
+		t.Fatalf("postDeviceAction returned error: %v", err)
+	}
+	want := map[string]string{"d2": "host is offline", "d3": "not acted on by the API"}
+	if !reflect.DeepEqual(failures, want) {
+		t.Errorf("failures = %v, want %v", failures, want)
+	}
+
+	call := (*calls)[0]
+	if call.Method != "POST" || call.Path != "/devices/entities/devices-actions/v2" || call.Query != "action_name=contain" {
+		t.Errorf("request = %s %s?%s", call.Method, call.Path, call.Query)
+	}
+	if call.Body != `{"ids":["d1","d2","d3"]}` {
+		t.Errorf("request body = %s", call.Body)
+	}
+}
+
+func TestPerformDeviceAction(t *testing.T) {
+	// The first batch fails as a whole; the second has one host missing from the response
+	batch := 0
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		batch++
+		if batch == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors":[{"code":400,"message":"bad batch"}]}`)
+			return
+		}
+		var req struct {
+			IDs []string `json:"ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var resources []map[string]string
+		for _, id := range req.IDs[1:] {
+			resources = append(resources, map[string]string{"id": id})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"resources": resources})
+	})
+
+	var ids []string
+	for i := 0; i < maxDeviceIDsPerAction+2; i++ {
+		ids = append(ids, fmt.Sprintf("d%d", i))
+	}
+	results := performDeviceAction(client, deviceAction{Name: "contain"}, ids, map[string]string{"d0": "web-0"})
+
+	if len(*calls) != 2 {
+		t.Fatalf("made %d requests, want 2 batches", len(*calls))
+	}
+	if len(results) != len(ids) {
+		t.Fatalf("got %d results, want %d", len(results), len(ids))
+	}
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Result]++
+	}
+	if counts["failed"] != maxDeviceIDsPerAction+1 || counts["ok"] != 1 {
+		t.Errorf("result counts = %v, want %d failed and 1 ok", counts, maxDeviceIDsPerAction+1)
+	}
+	if r := results[0]; r.Hostname != "web-0" || !strings.Contains(r.Error, "bad batch") {
+		t.Errorf("first result = %+v, want web-0 failed with the batch error", r)
+	}
+	if r := results[1]; r.Hostname != "(unknown)" {
+		t.Errorf("hostname of an unknown host = %q, want (unknown)", r.Hostname)
+	}
+	if r := results[maxDeviceIDsPerAction]; r.Result != "failed" || r.Error != "not acted on by the API" {
+		t.Errorf("result of a host missing from the response = %+v", r)
+	}
+}
+
+func TestRunDeviceAction(t *testing.T) {
+	contain := deviceAction{Name: "contain", Verb: "contain", Done: "Contained"}
+
+	t.Run("partial failure", func(t *testing.T) {
+		calls := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/devices/entities/devices/v2" {
+				devicesHandler(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"resources":[{"id":"d1"}],"errors":[{"id":"d2","code":409,"message":"host is offline"}]}`)
+		})
+		cmd, out := newTestCommand(func(cmd *cobra.Command) { addDeviceActionFlags(cmd, 10) })
+		if err := cmd.ParseFlags([]string{"--yes", "-o", "csv"}); err != nil {
+			t.Fatal(err)
+		}
+
+		err := runDeviceAction(cmd, []string{"d1", "d2", "d1"}, contain)
+		if err == nil || err.Error() != "failed to contain 1 of 2 hosts" {
+			t.Fatalf("runDeviceAction error = %v, want a failure for 1 of 2 hosts", err)
+		}
+		want := "hostname,device_id,result,error\nhost-d1,d1,ok,\nhost-d2,d2,failed,host is offline\n"
+		if out.String() != want {
+			t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+		}
+		if action := (*calls)[len(*calls)-1]; action.Body != `{"ids":["d1","d2"]}` {
+			t.Errorf("action request body = %s, want each host once", action.Body)
+		}
+	})
+
+	t.Run("all succeed", func(t *testing.T) {
+		newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/devices/entities/devices/v2" {
+				devicesHandler(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"resources":[{"id":"d1"}]}`)
+		})
+		cmd, _ := newTestCommand(func(cmd *cobra.Command) { addDeviceActionFlags(cmd, 10) })
+		if err := cmd.ParseFlags([]string{"--yes"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := runDeviceAction(cmd, []string{"d1"}, contain); err != nil {
+			t.Errorf("runDeviceAction returned error: %v", err)
+		}
+	})
+
+	t.Run("more than max hosts", func(t *testing.T) {
+		calls := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		})
+		cmd, _ := newTestCommand(func(cmd *cobra.Command) { addDeviceActionFlags(cmd, 10) })
+		if err := cmd.ParseFlags([]string{"--yes", "--max-hosts", "2"}); err != nil {
+			t.Fatal(err)
+		}
+
+		err := runDeviceAction(cmd, []string{"d1", "d2", "d3"}, contain)
+		if err == nil || !strings.Contains(err.Error(), "refusing to contain more than 2 hosts") {
+			t.Errorf("runDeviceAction error = %v, want a --max-hosts refusal", err)
+		}
+		if len(*calls) != 0 {
+			t.Errorf("made %d API calls before refusing", len(*calls))
+		}
+	})
+
+	t.Run("no terminal without --yes", func(t *testing.T) {
+		calls := newTestAPI(t, devicesHandler)
+		cmd, _ := newTestCommand(func(cmd *cobra.Command) { addDeviceActionFlags(cmd, 10) })
+
+		err := runDeviceAction(cmd, []string{"d1"}, contain)
+		if err == nil || !strings.Contains(err.Error(), "use --yes") {
+			t.Errorf("runDeviceAction error = %v, want a confirmation error", err)
+		}
+		for _, c := range *calls {
+			if c.Path == "/devices/entities/devices-actions/v2" {
+				t.Error("the action was sent without confirmation")
+			}
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/HARSH16DAWAR/falcon-cli/cmd/filter"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// maxDeviceIDsPerRequest is the maximum number of IDs accepted by the device entities endpoint
+const maxDeviceIDsPerRequest = 5000
+
+// Device represents a host returned by the device entities API
+type Device struct {
+	DeviceID        string   `json:"device_id"`
+	Hostname        string   `json:"hostname"`
+	PlatformName    string   `json:"platform_name"`
+	OSVersion       string   `json:"os_version"`
+	ProductTypeDesc string   `json:"product_type_desc"`
+	Status          string   `json:"status"`
+	FirstSeen       string   `json:"first_seen"`
+	LastSeen        string   `json:"last_seen"`
+	AgentVersion    string   `json:"agent_version"`
+	LocalIP         string   `json:"local_ip"`
+	ExternalIP      string   `json:"external_ip"`
+	MacAddress      string   `json:"mac_address"`
+	MachineDomain   string   `json:"machine_domain"`
+	Tags            []string `json:"tags"`
+	Groups          []string `json:"groups"`
+}
+
+// DevicesResponse represents the response from the device entities API
+type DevicesResponse struct {
+	Resources []Device `json:"resources"`
+	Errors    []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Meta utils.QueryMeta `json:"meta"`
+}
+
+// getDevices resolves host IDs to full device details, batching requests to the API's limit
+func getDevices(client *utils.FalconClient, ids []string) ([]Device, error) {
+	var devices []Device
+	for _, chunk := range utils.ChunkIDs(ids, maxDeviceIDsPerRequest) {
+		payload, err := json.Marshal(map[string][]string{"ids": chunk})
+		if err != nil {
+			return nil, fmt.Errorf("error encoding request: %v", err)
+		}
+
+		resp, err := client.PostWithRetry("/devices/entities/devices/v2", bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("error getting host details: %w", err)
+		}
+
+		var result DevicesResponse
+		if err := client.ParseResponse(resp, &result); err != nil {
+			return nil, err
+		}
+		devices = append(devices, result.Resources...)
+	}
+	return devices, nil
+}
+
+// deviceColumns are the default columns shown for host details
+var deviceColumns = []output.Column{
+	{Header: "HOSTNAME", Field: "hostname"},
+	{Header: "PLATFORM", Field: "platform_name"},
+	{Header: "OS VERSION", Field: "os_version"},
+	{Header: "LAST SEEN", Field: "last_seen"},
+	{Header: "AGENT VERSION", Field: "agent_version"},
+	{Header: "LOCAL IP", Field: "local_ip"},
+	{Header: "TAGS", Field: "tags"},
+}
+
+// hostsGetCmd represents the hosts get command
+var hostsGetCmd = &cobra.Command{
+	Use:   "get [HOST_ID...]",
+	Short: "Show full details for hosts",
+	Long: `Show hostname, platform, OS version, last seen time, agent version, local IP and tags for hosts.
+
+Host IDs can be given as arguments. Without arguments, hosts are selected with --filter or --filter-name
+and paginated the same way as the hosts command, including --hidden and --stale-days.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pageOpts, err := getPageOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Get filter value
+		filterValue, err := getFilterValue(cmd)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 && filterValue != "" {
+			return hostIDsWithFilterError(cmd)
+		}
+		if explainFilter(cmd, filterValue) {
+			return nil
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		printer, err := output.NewFromFlags(cmd, deviceColumns)
+		if err != nil {
+			return err
+		}
+
+		// printDevices resolves a batch of IDs and hands the devices to the printer
+		printDevices := func(ids []string) error {
+			devices, err := getDevices(client, ids)
+			if err != nil {
+				return err
+			}
+			for _, d := range devices {
+				if err := printer.Add(d); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if len(args) > 0 {
+			if err := printDevices(args); err != nil {
+				return err
+			}
+		} else {
+			params := make(map[string]string)
+			if filterValue != "" {
+				params["filter"] = filterValue
+			}
+
+			// Resolve each page of IDs as it arrives
+			_, _, err = client.QueryIDs(hostsEndpoint(cmd), params, pageOpts, func(ids []string, _ utils.QueryMeta) error {
+				return printDevices(ids)
+			})
+			if err != nil {
+				return fmt.Errorf("error getting hosts: %w", err)
+			}
+		}
+
+		return printer.Flush()
+	},
+}
+
+func init() {
+	addFilterFlags(hostsGetCmd, "hosts")
+	addStaleDaysFlag(hostsGetCmd)
+	hostsGetCmd.Flags().Bool("hidden", false, "Select hidden hosts with --filter or --filter-name")
+	addPageFlags(hostsGetCmd)
+	hostsCmd.AddCommand(hostsGetCmd)
+
+	// Show hostnames as filter test samples
+	filter.RegisterSampler("hosts", func(client *utils.FalconClient, ids []string) ([]string, error) {
+		devices, err := getDevices(client, ids)
+		if err != nil {
+			return nil, err
+		}
+		hostnames := make([]string, len(devices))
+		for i, d := range devices {
+			hostnames[i] = fmt.Sprintf("%s (%s)", d.Hostname, d.DeviceID)
+		}
+		return hostnames, nil
+	})
+}
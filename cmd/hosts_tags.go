@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// maxDeviceIDsPerTagUpdate is the maximum number of IDs accepted by the device tags endpoint
+const maxDeviceIDsPerTagUpdate = 500
+
+// groupingTagPrefix is the prefix of the tags that can be managed through the API
+const groupingTagPrefix = "FalconGroupingTags/"
+
+// groupingTagPattern matches a valid Falcon grouping tag
+var groupingTagPattern = regexp.MustCompile(`^FalconGroupingTags/[A-Za-z0-9_/-]+$`)
+
+// parseTags checks tags and adds the FalconGroupingTags/ prefix to tags given without it
+func parseTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("give at least one tag with --tag")
+	}
+
+	parsed := make([]string, len(tags))
+	for i, tag := range tags {
+		if !strings.Contains(tag, "/") {
+			tag = groupingTagPrefix + tag
+		}
+		if !strings.HasPrefix(tag, groupingTagPrefix) {
+			return nil, fmt.Errorf("invalid tag '%s': only %s tags can be changed through the API", tag, strings.TrimSuffix(groupingTagPrefix, "/"))
+		}
+		if !groupingTagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag '%s': tags may only contain letters, numbers, '_', '-' and '/' after %s", tag, groupingTagPrefix)
+		}
+		parsed[i] = tag
+	}
+	return parsed, nil
+}
+
+// updateTags adds or removes tags on hosts in batches the endpoint accepts,
+// reporting for each host whether its tags changed
+func updateTags(client *utils.FalconClient, action string, ids, tags []string, hostnames map[string]string) []ActionResult {
+	var results []ActionResult
+	for _, chunk := range utils.ChunkIDs(ids, maxDeviceIDsPerTagUpdate) {
+		updated, failures, err := patchTags(client, action, chunk, tags)
+		for _, id := range chunk {
+			result := ActionResult{DeviceID: id, Hostname: hostnameOrUnknown(hostnames, id), Result: "unchanged"}
+			if err != nil {
+				result.Result, result.Error = "failed", err.Error()
+			} else if msg, failed := failures[id]; failed {
+				result.Result, result.Error = "failed", msg
+			} else if updated[id] {
+				result.Result = "changed"
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// patchTags sends one batch to the device tags endpoint. It returns the hosts
+// whose tags changed and the error message of each host the API rejected.
+func patchTags(client *utils.FalconClient, action string, ids, tags []string) (map[string]bool, map[string]string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"action":     action,
+		"device_ids": ids,
+		"tags":       tags,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error encoding request: %v", err)
+	}
+
+	resp, err := client.Patch("/devices/entities/devices/tags/v1", bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result struct {
+		Resources []struct {
+			DeviceID string `json:"device_id"`
+			Code     int    `json:"code"`
+			Updated  bool   `json:"updated"`
+			Error    string `json:"error"`
+		} `json:"resources"`
+	}
+	if err := client.ParseResponse(resp, &result); err != nil {
+		return nil, nil, err
+	}
+
+	updated := make(map[string]bool)
+	failures := make(map[string]string)
+	for _, r := range result.Resources {
+		switch {
+		case r.Error != "":
+			failures[r.DeviceID] = r.Error
+		case r.Code >= 300:
+			failures[r.DeviceID] = fmt.Sprintf("status code %d", r.Code)
+		case r.Updated:
+			updated[r.DeviceID] = true
+		}
+	}
+	return updated, failures, nil
+}
+
+// runTagUpdate selects hosts and adds or removes the --tag tags, printing for each host whether it changed.
+// Hosts selected by filter are checked against --max-hosts and confirmed like device actions.
+func runTagUpdate(cmd *cobra.Command, args []string, action string) error {
+	maxHosts, _ := cmd.Flags().GetInt("max-hosts")
+	tagFlags, _ := cmd.Flags().GetStringArray("tag")
+	tags, err := parseTags(tagFlags)
+	if err != nil {
+		return err
+	}
+
+	// Get filter value
+	filterValue, err := getFilterValue(cmd)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && filterValue != "" {
+		return hostIDsWithFilterError(cmd)
+	}
+	if len(args) == 0 && filterValue == "" {
+		return fmt.Errorf("give host IDs, '-' to read them from standard input, --filter or --filter-name to select the hosts to %s tags", action)
+	}
+	if explainFilter(cmd, filterValue) {
+		return nil
+	}
+	if maxHosts <= 0 {
+		return fmt.Errorf("--max-hosts must be positive")
+	}
+
+	// Create Falcon client
+	client, err := utils.NewFalconClient()
+	if err != nil {
+		return fmt.Errorf("error creating Falcon client: %w", err)
+	}
+
+	limit := 0
+	if filterValue != "" {
+		limit = maxHosts + 1
+	}
+	ids, err := selectHostIDs(cmd, client, args, hostsQueryEndpoint, filterValue, limit)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		fmt.Println("No hosts to tag")
+		return nil
+	}
+	if filterValue != "" && len(ids) > maxHosts {
+		return fmt.Errorf("refusing to %s tags on more than %d hosts matching the filter; raise --max-hosts to change them all", action, maxHosts)
+	}
+
+	devices, err := getDevices(client, ids)
+	if err != nil {
+		return err
+	}
+	hostnames := make(map[string]string, len(devices))
+	for _, d := range devices {
+		hostnames[d.DeviceID] = d.Hostname
+	}
+
+	if filterValue != "" {
+		verb := fmt.Sprintf("%s %s %s", action, strings.Join(tags, ", "), map[string]string{"add": "to", "remove": "from"}[action])
+		proceed, err := confirmAction(cmd, deviceAction{Verb: verb}, ids, hostnames)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+
+	results := updateTags(client, action, ids, tags, hostnames)
+
+	printer, err := output.NewFromFlags(cmd, actionResultColumns)
+	if err != nil {
+		return err
+	}
+	changed, failed := 0, 0
+	for _, r := range results {
+		switch r.Result {
+		case "changed":
+			changed++
+		case "failed":
+			failed++
+		}
+		if err := printer.Add(r); err != nil {
+			return err
+		}
+	}
+	if err := printer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Changed %d of %d hosts (%d already up to date)\n", changed, len(results), len(results)-changed-failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to %s tags for %d of %d hosts", action, failed, len(results))
+	}
+	return nil
+}
+
+// hostTagColumns are the columns shown by hosts tags list
+var hostTagColumns = []output.Column{
+	{Header: "HOSTNAME", Field: "hostname"},
+	{Header: "DEVICE ID", Field: "device_id"},
+	{Header: "TAGS", Field: "tags"},
+}
+
+// hostsTagsCmd represents the hosts tags command
+var hostsTagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "Manage Falcon grouping tags on hosts",
+	Long: `List, add and remove the FalconGroupingTags tags of hosts, which host groups are often assigned by.
+
+Tags may be given with or without the FalconGroupingTags/ prefix. Sensor grouping tags are set when the sensor
+is installed and cannot be changed here.`,
+}
+
+// hostsTagsListCmd represents the hosts tags list command
+var hostsTagsListCmd = &cobra.Command{
+	Use:   "list [HOST_ID...|-]",
+	Short: "List the tags of hosts",
+	Long: `List the tags of hosts given as IDs, as '-' to read IDs from standard input, or selected with --filter
+or --filter-name.`,
+	Example: `  falcon-cli hosts tags list 1a2b3c4d5e6f
+  falcon-cli hosts tags list --filter "tags:'FalconGroupingTags/web'"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get filter value
+		filterValue, err := getFilterValue(cmd)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 && filterValue != "" {
+			return hostIDsWithFilterError(cmd)
+		}
+		if len(args) == 0 && filterValue == "" {
+			return fmt.Errorf("give host IDs, '-' to read them from standard input, --filter or --filter-name to select hosts")
+		}
+		if explainFilter(cmd, filterValue) {
+			return nil
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		ids, err := selectHostIDs(cmd, client, args, hostsQueryEndpoint, filterValue, 0)
+		if err != nil {
+			return err
+		}
+		devices, err := getDevices(client, ids)
+		if err != nil {
+			return err
+		}
+
+		printer, err := output.NewFromFlags(cmd, hostTagColumns)
+		if err != nil {
+			return err
+		}
+		for _, d := range devices {
+			if err := printer.Add(d); err != nil {
+				return err
+			}
+		}
+		return printer.Flush()
+	},
+}
+
+// hostsTagsAddCmd represents the hosts tags add command
+var hostsTagsAddCmd = &cobra.Command{
+	Use:   "add [HOST_ID...|-] --tag TAG",
+	Short: "Add tags to hosts",
+	Long: `Add FalconGroupingTags tags to hosts given as IDs, as '-' to read IDs from standard input, or selected
+with --filter or --filter-name. The result shows which hosts changed and which already had the tags.
+
+Tags drive host group and policy assignment, so hosts selected by filter are listed and confirmation is asked
+for; use --yes to skip the question in scripts. More than --max-hosts hosts are never changed by filter at once.`,
+	Example: `  falcon-cli hosts tags add 1a2b3c4d5e6f --tag web --tag FalconGroupingTags/prod
+  falcon-cli hosts tags add --filter "hostname:'web-*'" --tag web --max-hosts 500`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTagUpdate(cmd, args, "add")
+	},
+}
+
+// hostsTagsRemoveCmd represents the hosts tags remove command
+var hostsTagsRemoveCmd = &cobra.Command{
+	Use:   "remove [HOST_ID...|-] --tag TAG",
+	Short: "Remove tags from hosts",
+	Long: `Remove FalconGroupingTags tags from hosts given as IDs, as '-' to read IDs from standard input, or
+selected with --filter or --filter-name. The result shows which hosts changed and which did not have the tags.
+
+Hosts selected by filter are confirmed and capped with --yes and --max-hosts the same way as for 'tags add'.`,
+	Example: `  falcon-cli hosts tags remove 1a2b3c4d5e6f --tag web
+  falcon-cli hosts tags remove --filter "tags:'FalconGroupingTags/staging'" --tag staging`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTagUpdate(cmd, args, "remove")
+	},
+}
+
+func init() {
+	addFilterFlags(hostsTagsListCmd, "hosts")
+	addDeviceActionFlags(hostsTagsAddCmd, 100)
+	addDeviceActionFlags(hostsTagsRemoveCmd, 100)
+	hostsTagsAddCmd.Flags().StringArray("tag", nil, "Tag to add, with or without the FalconGroupingTags/ prefix (repeatable)")
+	hostsTagsRemoveCmd.Flags().StringArray("tag", nil, "Tag to remove, with or without the FalconGroupingTags/ prefix (repeatable)")
+	hostsTagsCmd.AddCommand(hostsTagsListCmd)
+	hostsTagsCmd.AddCommand(hostsTagsAddCmd)
+	hostsTagsCmd.AddCommand(hostsTagsRemoveCmd)
+	hostsCmd.AddCommand(hostsTagsCmd)
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "bare tag gets prefix", tags: []string{"web"}, want: []string{"FalconGroupingTags/web"}},
+		{name: "prefixed tag", tags: []string{"FalconGroupingTags/prod"}, want: []string{"FalconGroupingTags/prod"}},
+		{name: "nested tag", tags: []string{"FalconGroupingTags/env/prod-1_a"}, want: []string{"FalconGroupingTags/env/prod-1_a"}},
+		{name: "mixed", tags: []string{"web", "FalconGroupingTags/prod"}, want: []string{"FalconGroupingTags/web", "FalconGroupingTags/prod"}},
+		{name: "no tags", tags: nil, wantErr: "give at least one tag with --tag"},
+		{name: "sensor grouping tag", tags: []string{"SensorGroupingTags/x"}, wantErr: "only FalconGroupingTags tags can be changed"},
+		{name: "other prefix", tags: []string{"env/prod"}, wantErr: "only FalconGroupingTags tags can be changed"},
+		{name: "space", tags: []string{"my tag"}, wantErr: "tags may only contain"},
+		{name: "invalid character after prefix", tags: []string{"FalconGroupingTags/a:b"}, wantErr: "tags may only contain"},
+		{name: "empty after prefix", tags: []string{"FalconGroupingTags/"}, wantErr: "tags may only contain"},
+		{name: "one invalid in batch", tags: []string{"web", "we$b"}, wantErr: "invalid tag 'FalconGroupingTags/we$b'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTags(tt.tags)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseTags(%q) error = %v, want %q", tt.tags, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTags(%q) returned error: %v", tt.tags, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateTags(t *testing.T) {
+	client, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resources":[
+			{"device_id":"d1","code":200,"updated":true},
+			{"device_id":"d2","code":200,"updated":false},
+			{"device_id":"d3","code":404,"updated":false},
+			{"device_id":"d4","code":400,"updated":false,"error":"invalid tag"}
+		]}`)
+	})
+
+	results := updateTags(client, "add", []string{"d1", "d2", "d3", "d4", "d5"}, []string{"FalconGroupingTags/web"}, map[string]string{"d1": "web-1"})
+
+	want := []ActionResult{
+		{Hostname: "web-1", DeviceID: "d1", Result: "changed"},
+		{Hostname: "(unknown)", DeviceID: "d2", Result: "unchanged"},
+		{Hostname: "(unknown)", DeviceID: "d3", Result: "failed", Error: "status code 404"},
+		{Hostname: "(unknown)", DeviceID: "d4", Result: "failed", Error: "invalid tag"},
+		{Hostname: "(unknown)", DeviceID: "d5", Result: "unchanged"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("updateTags =\n%+v\nwant\n%+v", results, want)
+	}
+
+	call := (*calls)[0]
+	if call.Method != "PATCH" || call.Path != "/devices/entities/devices/tags/v1" {
+		t.Errorf("request = %s %s", call.Method, call.Path)
+	}
+	wantBody := `{"action":"add","device_ids":["d1","d2","d3","d4","d5"],"tags":["FalconGroupingTags/web"]}`
+	if call.Body != wantBody {
+		t.Errorf("request body = %s, want %s", call.Body, wantBody)
+	}
+}
+
+func TestUpdateTagsBatchError(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors":[{"code":403,"message":"access denied"}]}`)
+	})
+
+	results := updateTags(client, "remove", []string{"d1", "d2"}, []string{"FalconGroupingTags/web"}, nil)
+	for _, r := range results {
+		if r.Result != "failed" || !strings.Contains(r.Error, "access denied") {
+			t.Errorf("result = %+v, want failed with the API error", r)
+		}
+	}
+}
+
+func TestRunTagUpdateByFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []string
+		wantErr string
+	}{
+		{name: "over max hosts", flags: []string{"--yes", "--max-hosts", "2"}, wantErr: "refusing to add tags on more than 2 hosts"},
+		{name: "no confirmation", flags: nil, wantErr: "use --yes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/devices/entities/devices/v2" {
+					devicesHandler(w, r)
+					return
+				}
+				fmt.Fprint(w, `{"resources":["d1","d2","d3"],"meta":{"pagination":{"offset":"","limit":100,"total":3}}}`)
+			})
+			cmd, _ := newTestCommand(func(cmd *cobra.Command) {
+				addDeviceActionFlags(cmd, 10)
+				cmd.Flags().StringArray("tag", nil, "")
+			})
+			if err := cmd.ParseFlags(append([]string{"--tag", "web", "--filter", "hostname:'web-*'"}, tt.flags...)); err != nil {
+				t.Fatal(err)
+			}
+
+			err := runTagUpdate(cmd, nil, "add")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("runTagUpdate error = %v, want %q", err, tt.wantErr)
+			}
+			for _, c := range *calls {
+				if c.Method == "PATCH" {
+					t.Error("tags were changed")
+				}
+			}
+		})
+	}
+}
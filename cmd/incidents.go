@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/HARSH16DAWAR/falcon-cli/cmd/filter"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// incidentsQueryEndpoint is the query endpoint for incident IDs
+const incidentsQueryEndpoint = "/incidents/queries/incidents/v1"
+
+// getIncidentsFilterValue returns the incidents filter selected by --filter, --filter-name and --param
+func getIncidentsFilterValue(cmd *cobra.Command) (string, error) {
+	return filter.FromFlags(cmd, "incidents")
+}
+
+// incidentIDColumns are the columns shown when listing incident IDs
+var incidentIDColumns = []output.Column{
+	{Header: "INCIDENT ID"},
+}
+
+// incidentsCmd represents the incidents command
+var incidentsCmd = &cobra.Command{
+	Use:   "incidents",
+	Short: "List incidents in your Falcon environment",
+	Long: `List the IDs of incidents in your Falcon environment. You can filter incidents using the --filter flag
+or saved filters of type incidents using --filter-name, and order them with --sort.
+
+Results are paginated with --limit and --offset. Use --all to walk every page, or --max-results to stop after a given number of incidents.
+Use 'incidents get' to show incident details, 'incidents behaviors' to list what happened in them and
+'incidents update' to triage them.`,
+	Example: `  falcon-cli incidents --filter "state:'open'+fine_score:>=80" --sort fine_score.desc
+  falcon-cli incidents --filter "start:>'now-7d'" --count-only`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Get filter value
+		filterValue, err := getIncidentsFilterValue(cmd)
+		if err != nil {
+			return err
+		}
+		if explainFilter(cmd, filterValue) {
+			return nil
+		}
+
+		// Get pagination options
+		pageOpts, err := getPageOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		params := getQueryParams(cmd, filterValue)
+
+		// Only read the total when just the count is wanted
+		if countOnly, _ := cmd.Flags().GetBool("count-only"); countOnly {
+			total, meta, err := client.Count(incidentsQueryEndpoint, params)
+			if err != nil {
+				return fmt.Errorf("error counting incidents: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), total)
+			fmt.Fprintf(os.Stderr, "Query time: %.3fs\n", meta.QueryTime)
+			return nil
+		}
+
+		printer, err := output.NewFromFlags(cmd, incidentIDColumns)
+		if err != nil {
+			return err
+		}
+
+		// Stream incident IDs as each page arrives
+		count, meta, err := client.QueryIDs(incidentsQueryEndpoint, params, pageOpts, func(ids []string, _ utils.QueryMeta) error {
+			for _, id := range ids {
+				if err := printer.Add(id); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error getting incidents: %w", err)
+		}
+		if err := printer.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Found %d of %d incidents\n", count, meta.Pagination.Total)
+
+		return nil
+	},
+}
+
+func init() {
+	addFilterFlags(incidentsCmd, "incidents")
+	addSortFlag(incidentsCmd, "start.desc")
+	incidentsCmd.Flags().Bool("count-only", false, "Print the number of matching incidents instead of their IDs")
+	addPageFlags(incidentsCmd)
+}
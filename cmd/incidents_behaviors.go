@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/fql"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// Behavior represents a behavior returned by the behavior entities API
+type Behavior struct {
+	BehaviorID         string   `json:"behavior_id"`
+	IncidentID         string   `json:"incident_id"`
+	IncidentIDs        []string `json:"incident_ids"`
+	AID                string   `json:"aid"`
+	Hostname           string   `json:"hostname"` // Set from the incident's hosts by getBehaviors
+	Timestamp          string   `json:"timestamp"`
+	DisplayName        string   `json:"display_name"`
+	Objective          string   `json:"objective"`
+	Tactic             string   `json:"tactic"`
+	Technique          string   `json:"technique"`
+	PatternDisposition int      `json:"pattern_disposition"`
+	UserName           string   `json:"user_name"`
+	Filepath           string   `json:"filepath"`
+	Cmdline            string   `json:"cmdline"`
+	SHA256             string   `json:"sha256"`
+	DetectionIDs       []string `json:"detection_ids"`
+}
+
+// BehaviorsResponse represents the response from the behavior entities API
+type BehaviorsResponse struct {
+	Resources []Behavior `json:"resources"`
+	Errors    []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Meta utils.QueryMeta `json:"meta"`
+}
+
+// getBehaviors returns the behaviors of incidents, with the hostnames of the
+// incidents' hosts filled in
+func getBehaviors(client *utils.FalconClient, incidents []Incident) ([]Behavior, error) {
+	hostnames := make(map[string]string)
+	quoted := make([]string, len(incidents))
+	for i, incident := range incidents {
+		quoted[i] = fql.Quote(incident.IncidentID)
+		for _, h := range incident.Hosts {
+			hostnames[h.DeviceID] = h.Hostname
+		}
+	}
+
+	var ids []string
+	params := map[string]string{"filter": "incident_id:[" + strings.Join(quoted, ",") + "]"}
+	_, _, err := client.QueryIDs("/incidents/queries/behaviors/v1", params, utils.PageOptions{Limit: maxIncidentIDsPerRequest, All: true}, func(page []string, _ utils.QueryMeta) error {
+		ids = append(ids, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting behaviors: %w", err)
+	}
+
+	var behaviors []Behavior
+	for _, chunk := range utils.ChunkIDs(ids, maxIncidentIDsPerRequest) {
+		payload, err := json.Marshal(map[string][]string{"ids": chunk})
+		if err != nil {
+			return nil, fmt.Errorf("error encoding request: %v", err)
+		}
+
+		resp, err := client.PostWithRetry("/incidents/entities/behaviors/GET/v1", bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("error getting behavior details: %w", err)
+		}
+
+		var result BehaviorsResponse
+		if err := client.ParseResponse(resp, &result); err != nil {
+			return nil, err
+		}
+		for _, b := range result.Resources {
+			b.Hostname = hostnames[b.AID]
+			behaviors = append(behaviors, b)
+		}
+	}
+	return behaviors, nil
+}
+
+// behaviorColumns are the default columns shown for behaviors
+var behaviorColumns = []output.Column{
+	{Header: "TIMESTAMP", Field: "timestamp"},
+	{Header: "HOSTNAME", Field: "hostname"},
+	{Header: "NAME", Field: "display_name"},
+	{Header: "TACTIC", Field: "tactic"},
+	{Header: "TECHNIQUE", Field: "technique"},
+	{Header: "USER", Field: "user_name"},
+	{Header: "COMMAND LINE", Field: "cmdline"},
+	{Header: "INCIDENT ID", Field: "incident_id"},
+}
+
+// incidentsBehaviorsCmd represents the incidents behaviors command
+var incidentsBehaviorsCmd = &cobra.Command{
+	Use:   "behaviors INCIDENT_ID...",
+	Short: "List the behaviors that make up incidents",
+	Long: `List the behaviors of incidents: the time, host, name, tactic, technique, user and command line of each.
+Use -o json or -o yaml to see every field of the behaviors.`,
+	Example: `  falcon-cli incidents behaviors inc:1a2b3c:4d5e6f
+  falcon-cli incidents behaviors -o json inc:1a2b3c:4d5e6f inc:1a2b3c:7a8b9c`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		// Look up the incidents first, for the hostnames of their hosts
+		incidents, err := getIncidents(client, args)
+		if err != nil {
+			return err
+		}
+		if len(incidents) == 0 {
+			return fmt.Errorf("incidents not found: %s", strings.Join(args, ", "))
+		}
+
+		behaviors, err := getBehaviors(client, incidents)
+		if err != nil {
+			return err
+		}
+
+		printer, err := output.NewFromFlags(cmd, behaviorColumns)
+		if err != nil {
+			return err
+		}
+		for _, b := range behaviors {
+			if err := printer.Add(b); err != nil {
+				return err
+			}
+		}
+		return printer.Flush()
+	},
+}
+
+func init() {
+	incidentsCmd.AddCommand(incidentsBehaviorsCmd)
+}
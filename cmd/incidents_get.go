@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/HARSH16DAWAR/falcon-cli/cmd/filter"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// maxIncidentIDsPerRequest is the maximum number of IDs accepted by the incident and behavior entities endpoints
+const maxIncidentIDsPerRequest = 500
+
+// incidentStatuses maps the numeric incident statuses used by the API to their names
+var incidentStatuses = map[int]string{
+	20: "new",
+	25: "reopened",
+	30: "in_progress",
+	40: "closed",
+}
+
+// IncidentHost is a host involved in an incident
+type IncidentHost struct {
+	DeviceID     string `json:"device_id"`
+	Hostname     string `json:"hostname"`
+	PlatformName string `json:"platform_name"`
+	OSVersion    string `json:"os_version"`
+	LocalIP      string `json:"local_ip"`
+	ExternalIP   string `json:"external_ip"`
+	LastSeen     string `json:"last_seen"`
+}
+
+// Incident represents an incident returned by the incident entities API
+type Incident struct {
+	IncidentID        string         `json:"incident_id"`
+	Name              string         `json:"name"`
+	Description       string         `json:"description"`
+	State             string         `json:"state"`
+	Status            int            `json:"status"`
+	StatusName        string         `json:"status_name"` // Set from Status by getIncidents
+	FineScore         int            `json:"fine_score"`
+	Start             string         `json:"start"`
+	End               string         `json:"end"`
+	Created           string         `json:"created"`
+	ModifiedTimestamp string         `json:"modified_timestamp"`
+	Hosts             []IncidentHost `json:"hosts"`
+	Hostnames         []string       `json:"hostnames"` // Set from Hosts by getIncidents
+	Users             []string       `json:"users"`
+	Tactics           []string       `json:"tactics"`
+	Techniques        []string       `json:"techniques"`
+	Objectives        []string       `json:"objectives"`
+	Tags              []string       `json:"tags"`
+	AssignedTo        string         `json:"assigned_to"`
+	AssignedToName    string         `json:"assigned_to_name"`
+	Behaviors         []Behavior     `json:"behaviors,omitempty"`      // Set by attachBehaviors
+	BehaviorNames     []string       `json:"behavior_names,omitempty"` // Set from Behaviors by attachBehaviors
+}
+
+// IncidentsResponse represents the response from the incident entities API
+type IncidentsResponse struct {
+	Resources []Incident `json:"resources"`
+	Errors    []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Meta utils.QueryMeta `json:"meta"`
+}
+
+// getIncidents resolves incident IDs to full incident details, batching requests to the API's limit
+func getIncidents(client *utils.FalconClient, ids []string) ([]Incident, error) {
+	var incidents []Incident
+	for _, chunk := range utils.ChunkIDs(ids, maxIncidentIDsPerRequest) {
+		payload, err := json.Marshal(map[string][]string{"ids": chunk})
+		if err != nil {
+			return nil, fmt.Errorf("error encoding request: %v", err)
+		}
+
+		resp, err := client.PostWithRetry("/incidents/entities/incidents/GET/v1", bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("error getting incident details: %w", err)
+		}
+
+		var result IncidentsResponse
+		if err := client.ParseResponse(resp, &result); err != nil {
+			return nil, err
+		}
+		for _, incident := range result.Resources {
+			incident.StatusName = incidentStatuses[incident.Status]
+			for _, h := range incident.Hosts {
+				incident.Hostnames = append(incident.Hostnames, h.Hostname)
+			}
+			incidents = append(incidents, incident)
+		}
+	}
+	return incidents, nil
+}
+
+// attachBehaviors looks up the behaviors of incidents and adds them to each incident
+func attachBehaviors(client *utils.FalconClient, incidents []Incident) error {
+	if len(incidents) == 0 {
+		return nil
+	}
+	behaviors, err := getBehaviors(client, incidents)
+	if err != nil {
+		return err
+	}
+
+	index := make(map[string]int, len(incidents))
+	for i, incident := range incidents {
+		index[incident.IncidentID] = i
+	}
+	for _, b := range behaviors {
+		if i, ok := index[b.IncidentID]; ok {
+			incidents[i].Behaviors = append(incidents[i].Behaviors, b)
+			incidents[i].BehaviorNames = append(incidents[i].BehaviorNames, b.DisplayName)
+		}
+	}
+	return nil
+}
+
+// incidentColumns are the default columns shown for incident details
+var incidentColumns = []output.Column{
+	{Header: "START", Field: "start"},
+	{Header: "FINE SCORE", Field: "fine_score"},
+	{Header: "STATUS", Field: "status_name"},
+	{Header: "HOSTS", Field: "hostnames"},
+	{Header: "TACTICS", Field: "tactics"},
+	{Header: "ASSIGNED TO", Field: "assigned_to_name"},
+	{Header: "TAGS", Field: "tags"},
+	{Header: "INCIDENT ID", Field: "incident_id"},
+}
+
+// incidentsGetCmd represents the incidents get command
+var incidentsGetCmd = &cobra.Command{
+	Use:   "get [INCIDENT_ID...]",
+	Short: "Show full details for incidents",
+	Long: `Show start time, fine score, status, hosts, tactics, assignee and tags for incidents. Use -o json or
+-o yaml to see every field, including the details of each host involved.
+
+Use --behaviors to also look up the behaviors that make up each incident: their names are added as a column,
+and -o json or -o yaml include every field of each behavior. 'incidents behaviors' lists behaviors one per row.
+
+Incident IDs can be given as arguments. Without arguments, incidents are selected with --filter or --filter-name,
+ordered with --sort and paginated the same way as the incidents command.`,
+	Example: `  falcon-cli incidents get --filter "state:'open'" --sort fine_score.desc --limit 20
+  falcon-cli incidents get -o json inc:1a2b3c:4d5e6f
+  falcon-cli incidents get --behaviors -o yaml inc:1a2b3c:4d5e6f`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pageOpts, err := getPageOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Get filter value
+		filterValue, err := getIncidentsFilterValue(cmd)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 && filterValue != "" {
+			return fmt.Errorf("cannot use incident IDs together with --filter or --filter-name")
+		}
+		if explainFilter(cmd, filterValue) {
+			return nil
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		withBehaviors, _ := cmd.Flags().GetBool("behaviors")
+		columns := incidentColumns
+		if withBehaviors {
+			columns = append(slices.Clone(incidentColumns), output.Column{Header: "BEHAVIORS", Field: "behavior_names"})
+		}
+		printer, err := output.NewFromFlags(cmd, columns)
+		if err != nil {
+			return err
+		}
+
+		// printIncidents resolves a batch of IDs and hands the incidents to the printer
+		printIncidents := func(ids []string) error {
+			incidents, err := getIncidents(client, ids)
+			if err != nil {
+				return err
+			}
+			if withBehaviors {
+				if err := attachBehaviors(client, incidents); err != nil {
+					return err
+				}
+			}
+			for _, incident := range incidents {
+				if err := printer.Add(incident); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if len(args) > 0 {
+			if err := printIncidents(args); err != nil {
+				return err
+			}
+		} else {
+			// Resolve each page of IDs as it arrives
+			_, _, err = client.QueryIDs(incidentsQueryEndpoint, getQueryParams(cmd, filterValue), pageOpts, func(ids []string, _ utils.QueryMeta) error {
+				return printIncidents(ids)
+			})
+			if err != nil {
+				return fmt.Errorf("error getting incidents: %w", err)
+			}
+		}
+
+		return printer.Flush()
+	},
+}
+
+func init() {
+	addFilterFlags(incidentsGetCmd, "incidents")
+	addSortFlag(incidentsGetCmd, "start.desc")
+	addPageFlags(incidentsGetCmd)
+	incidentsGetCmd.Flags().Bool("behaviors", false, "Also show the behaviors of each incident")
+	incidentsCmd.AddCommand(incidentsGetCmd)
+
+	// Show incident scores and hosts as filter test samples
+	filter.RegisterSampler("incidents", func(client *utils.FalconClient, ids []string) ([]string, error) {
+		incidents, err := getIncidents(client, ids)
+		if err != nil {
+			return nil, err
+		}
+		labels := make([]string, len(incidents))
+		for i, incident := range incidents {
+			labels[i] = fmt.Sprintf("score %d on %d hosts (%s)", incident.FineScore, len(incident.Hosts), incident.IncidentID)
+		}
+		return labels, nil
+	})
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// parseIncidentStatus returns the numeric status for a status name such as
+// in_progress, or for a numeric status such as 30
+func parseIncidentStatus(status string) (string, error) {
+	for code, name := range incidentStatuses {
+		if status == name || status == strconv.Itoa(code) {
+			return strconv.Itoa(code), nil
+		}
+	}
+	return "", fmt.Errorf("invalid --status '%s' (valid statuses: new, reopened, in_progress, closed)", status)
+}
+
+// incidentActions returns the changes requested by the incidents update flags
+func incidentActions(cmd *cobra.Command) ([]ActionParameter, error) {
+	status, _ := cmd.Flags().GetString("status")
+	assign, _ := cmd.Flags().GetString("assign")
+	unassign, _ := cmd.Flags().GetBool("unassign")
+	addTags, _ := cmd.Flags().GetStringArray("add-tag")
+	removeTags, _ := cmd.Flags().GetStringArray("remove-tag")
+	comment, _ := cmd.Flags().GetString("comment")
+
+	var actions []ActionParameter
+	if status != "" {
+		code, err := parseIncidentStatus(status)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, ActionParameter{Name: "update_status", Value: code})
+	}
+	if assign != "" && unassign {
+		return nil, fmt.Errorf("cannot use --assign together with --unassign")
+	}
+	if assign != "" {
+		actions = append(actions, ActionParameter{Name: "update_assigned_to_v2", Value: assign})
+	}
+	if unassign {
+		actions = append(actions, ActionParameter{Name: "unassign", Value: ""})
+	}
+	for _, tag := range addTags {
+		actions = append(actions, ActionParameter{Name: "add_tag", Value: tag})
+	}
+	for _, tag := range removeTags {
+		actions = append(actions, ActionParameter{Name: "delete_tag", Value: tag})
+	}
+	if comment != "" {
+		actions = append(actions, ActionParameter{Name: "add_comment", Value: comment})
+	}
+
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("nothing to update; use --status, --assign, --unassign, --add-tag, --remove-tag or --comment")
+	}
+	return actions, nil
+}
+
+// updateIncidents applies actions to incidents, batching requests to the API's limit.
+// It returns the number of incidents updated before any error.
+func updateIncidents(client *utils.FalconClient, ids []string, actions []ActionParameter) (int, error) {
+	updated := 0
+	for _, chunk := range utils.ChunkIDs(ids, maxIncidentIDsPerRequest) {
+		payload, err := json.Marshal(map[string]interface{}{
+			"ids":               chunk,
+			"action_parameters": actions,
+		})
+		if err != nil {
+			return updated, fmt.Errorf("error encoding request: %v", err)
+		}
+
+		resp, err := client.Post("/incidents/entities/incident-actions/v1", bytes.NewReader(payload))
+		if err != nil {
+			return updated, fmt.Errorf("error updating incidents: %w", err)
+		}
+		resp.Body.Close()
+		updated += len(chunk)
+	}
+	return updated, nil
+}
+
+// incidentsUpdateCmd represents the incidents update command
+var incidentsUpdateCmd = &cobra.Command{
+	Use:   "update [INCIDENT_ID...]",
+	Short: "Change the status, assignee, tags or comments of incidents",
+	Long: `Change incidents with the incident actions endpoint. Several changes can be made at once, for example
+closing incidents and adding a comment explaining why. Incidents are assigned by user UUID.
+
+Incident IDs can be given as arguments. Without arguments, the incidents matching --filter or --filter-name are
+counted, confirmed and capped with --yes and --max-incidents the same way as for 'alerts update'.`,
+	Example: `  falcon-cli incidents update inc:1a2b3c:4d5e6f --status in_progress --assign 0a1b2c3d-4e5f-6789-abcd-ef0123456789
+  falcon-cli incidents update --filter "status:20+fine_score:<20" --status closed --comment "Low score, reviewed"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		actions, err := incidentActions(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Get filter value
+		filterValue, err := getIncidentsFilterValue(cmd)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 && filterValue != "" {
+			return fmt.Errorf("cannot use incident IDs together with --filter or --filter-name")
+		}
+		if len(args) == 0 && filterValue == "" {
+			return fmt.Errorf("give incident IDs, --filter or --filter-name to select the incidents to update")
+		}
+		if explainFilter(cmd, filterValue) {
+			return nil
+		}
+
+		// Create Falcon client
+		client, err := utils.NewFalconClient()
+		if err != nil {
+			return fmt.Errorf("error creating Falcon client: %w", err)
+		}
+
+		ids := args
+		if len(ids) == 0 {
+			ids, err = selectForUpdate(cmd, client, "incidents", incidentsQueryEndpoint, filterValue, actions)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+		}
+
+		updated, err := updateIncidents(client, ids, actions)
+		if err != nil {
+			if updated > 0 {
+				fmt.Printf("Updated %d of %d incidents before the error\n", updated, len(ids))
+			}
+			return err
+		}
+
+		fmt.Printf("Updated %d incidents\n", updated)
+		return nil
+	},
+}
+
+func init() {
+	addFilterFlags(incidentsUpdateCmd, "incidents")
+	addBulkUpdateFlags(incidentsUpdateCmd, "incidents", 100)
+	incidentsUpdateCmd.Flags().String("status", "", "Set the status: new, reopened, in_progress or closed")
+	incidentsUpdateCmd.Flags().String("assign", "", "Assign the incidents to the user with this UUID")
+	incidentsUpdateCmd.Flags().Bool("unassign", false, "Remove the assignee")
+	incidentsUpdateCmd.Flags().StringArray("add-tag", nil, "Add a tag (repeatable)")
+	incidentsUpdateCmd.Flags().StringArray("remove-tag", nil, "Remove a tag (repeatable)")
+	incidentsUpdateCmd.Flags().String("comment", "", "Add a comment")
+	incidentsUpdateCmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions([]string{"new", "reopened", "in_progress", "closed"}, cobra.ShellCompDirectiveNoFileComp))
+	incidentsCmd.AddCommand(incidentsUpdateCmd)
+}
@@ -13,6 +13,8 @@ import (
 
 	"github.com/HARSH16DAWAR/falcon-cli/cmd/config"
 	"github.com/HARSH16DAWAR/falcon-cli/cmd/filter"
+	"github.com/HARSH16DAWAR/falcon-cli/utils"
+	"github.com/HARSH16DAWAR/falcon-cli/utils/output"
 )
 
 var cfgFile string
@@ -26,6 +28,12 @@ Now you might think doesn't CrowdStrike have a falcon-cli itself. Well yes but t
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+
+	// Flags have been parsed by the time this runs, so any later error is not a
+	// usage problem and printing the usage text would only bury the error
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -33,7 +41,7 @@ Now you might think doesn't CrowdStrike have a falcon-cli itself. Well yes but t
 func Execute() {
 	err := RootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(utils.ExitCode(err))
 	}
 }
 
@@ -46,6 +54,11 @@ func init() {
 	// will be global for your application.
 
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.falcon-cli/config.yaml)")
+	RootCmd.PersistentFlags().StringVar(&utils.ProfileName, "profile", "", "Credential profile to use (default is $FALCON_PROFILE or the active profile)")
+	RootCmd.PersistentFlags().BoolVarP(&utils.Verbose, "verbose", "v", false, "Log API retries and other diagnostics to stderr")
+	RootCmd.PersistentFlags().Bool("no-validate", false, "Send filter expressions to the API without checking them first")
+	output.AddFlags(RootCmd)
+	RootCmd.RegisterFlagCompletionFunc("profile", config.CompleteProfileNames)
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -64,7 +77,7 @@ func initConfig() {
 
 		// Create .falcon-cli directory if it doesn't exist
 		falconDir := filepath.Join(home, ".falcon-cli")
-		err = os.MkdirAll(falconDir, 0755)
+		err = os.MkdirAll(falconDir, 0700)
 		cobra.CheckErr(err)
 
 		// Search config in .falcon-cli directory
@@ -75,6 +88,9 @@ func initConfig() {
 
 	viper.AutomaticEnv() // read in environment variables that match
 
+	// The config file can hold client secrets, so keep it private to the user
+	viper.SetConfigPermissions(0600)
+
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err != nil {
 		// It's okay if there's no config file
@@ -83,11 +99,20 @@ func initConfig() {
 		}
 	} else {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+		if err := utils.SecureConfigFile(viper.ConfigFileUsed()); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning:", err)
+		}
 	}
 }
 
 func addSubCommands() {
 	RootCmd.AddCommand(config.InitCmd)
+	RootCmd.AddCommand(config.GetCommand())
 	RootCmd.AddCommand(hostsCmd)
+	RootCmd.AddCommand(hostGroupsCmd)
+	RootCmd.AddCommand(alertsCmd)
+	RootCmd.AddCommand(incidentsCmd)
 	RootCmd.AddCommand(filter.GetCommand())
+	RootCmd.AddCommand(authCmd)
+	RootCmd.AddCommand(completionCmd)
 }
@@ -3,18 +3,17 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/spf13/viper"
 )
 
 const (
-	tokenEndpoint = "/oauth2/token"
+	tokenEndpoint  = "/oauth2/token"
+	revokeEndpoint = "/oauth2/revoke"
 )
 
 // RegionBaseURL maps region codes to their base URLs
@@ -38,6 +37,17 @@ type TokenManager struct {
 	token     string
 	expiresAt time.Time
 	mu        sync.RWMutex
+
+	// fixed holds the credentials of a standalone token manager, which are used
+	// instead of the active profile's and whose tokens are never cached on disk
+	fixed *fixedCredentials
+}
+
+// fixedCredentials are the credentials of a standalone token manager
+type fixedCredentials struct {
+	clientID     string
+	clientSecret string
+	cloudRegion  string
 }
 
 var (
@@ -53,6 +63,19 @@ func GetTokenManager() *TokenManager {
 	return tokenManager
 }
 
+// NewStandaloneTokenManager returns a token manager for the given credentials,
+// starting with token if it is not nil. It is not tied to the active profile and
+// never reads or writes the token cache, so it can be used for credentials that
+// have not been saved yet.
+func NewStandaloneTokenManager(clientID, clientSecret, cloudRegion string, token *TokenResponse) *TokenManager {
+	tm := &TokenManager{fixed: &fixedCredentials{clientID: clientID, clientSecret: clientSecret, cloudRegion: cloudRegion}}
+	if token != nil {
+		tm.token = token.AccessToken
+		tm.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn-60) * time.Second)
+	}
+	return tm
+}
+
 // GetToken returns a valid bearer token, refreshing if necessary
 func (tm *TokenManager) GetToken() (string, error) {
 	tm.mu.RLock()
@@ -64,7 +87,28 @@ func (tm *TokenManager) GetToken() (string, error) {
 	tm.mu.RUnlock()
 
 	// Token is invalid or expired, get a new one
-	return tm.refreshToken()
+	return tm.refreshToken(false)
+}
+
+// Refresh requests a new token even if the current one is still valid
+func (tm *TokenManager) Refresh() (string, error) {
+	return tm.refreshToken(true)
+}
+
+// ExpiresAt returns the expiry time of the current token, loading it from the
+// on-disk cache if no token has been fetched yet. It returns the zero time if
+// there is no valid token.
+func (tm *TokenManager) ExpiresAt() time.Time {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if !tm.isTokenValid() {
+		tm.loadCachedToken()
+	}
+	if !tm.isTokenValid() {
+		return time.Time{}
+	}
+	return tm.expiresAt
 }
 
 // isTokenValid checks if the current token is still valid
@@ -72,124 +116,224 @@ func (tm *TokenManager) isTokenValid() bool {
 	return tm.token != "" && time.Now().Before(tm.expiresAt)
 }
 
-// refreshToken gets a new token from the Falcon API
-func (tm *TokenManager) refreshToken() (string, error) {
+// Invalidate discards the cached token if it is still the given token, so the
+// next call to GetToken requests a new one. Passing the rejected token avoids
+// discarding a token that another request has already refreshed.
+func (tm *TokenManager) Invalidate(token string) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
-	// Double check if token was refreshed by another goroutine
-	if tm.isTokenValid() {
-		return tm.token, nil
+	if tm.token == token {
+		tm.token = ""
+		tm.expiresAt = time.Time{}
+
+		if tm.fixed == nil {
+			clientID, cloudRegion := tokenCacheKey()
+			RemoveCachedToken(clientID, cloudRegion)
+		}
 	}
+}
 
-	clientID := viper.GetString("falcon.client_id")
-	clientSecret := viper.GetString("falcon.client_secret")
-	cloudRegion := viper.GetString("falcon.cloud_region")
+// Revoke revokes the current token with the Falcon API and removes it from the
+// on-disk cache. It does nothing if there is no valid token.
+func (tm *TokenManager) Revoke() error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
 
-	if clientID == "" || clientSecret == "" {
-		return "", fmt.Errorf("Falcon credentials not found. Please run 'falcon-cli init' first")
+	if !tm.isTokenValid() {
+		tm.loadCachedToken()
+	}
+	if !tm.isTokenValid() {
+		return nil
 	}
 
-	// Get the base URL for the region
-	baseURL, ok := RegionBaseURL[cloudRegion]
-	if !ok {
-		return "", fmt.Errorf("invalid cloud region: %s", cloudRegion)
+	clientID, clientSecret, cloudRegion, err := tm.credentials()
+	if err != nil {
+		return err
+	}
+	if err := revokeToken(clientID, clientSecret, cloudRegion, tm.token); err != nil {
+		return err
 	}
 
-	// Construct the token request URL
-	tokenURL := fmt.Sprintf("%s/oauth2/token", baseURL)
+	tm.token = ""
+	tm.expiresAt = time.Time{}
+	if tm.fixed != nil {
+		return nil
+	}
+	return RemoveCachedToken(clientID, cloudRegion)
+}
 
-	// Create form data
-	formData := url.Values{}
-	formData.Set("grant_type", "client_credentials")
+// credentials returns the client ID, secret and cloud region tokens are
+// requested with: the fixed ones of a standalone manager, or the active profile's
+func (tm *TokenManager) credentials() (string, string, string, error) {
+	if tm.fixed != nil {
+		return tm.fixed.clientID, tm.fixed.clientSecret, tm.fixed.cloudRegion, nil
+	}
 
-	// Create the request
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(formData.Encode()))
+	profile, err := ActiveProfile()
+	if err != nil {
+		return "", "", "", err
+	}
+	clientSecret, err := profile.Secret()
 	if err != nil {
-		return "", fmt.Errorf("error creating token request: %v", err)
+		return "", "", "", err
 	}
+	return profile.ClientID, clientSecret, profile.CloudRegion, nil
+}
 
-	// Add required headers
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	req.SetBasicAuth(clientID, clientSecret)
+// refreshToken gets a new token from the on-disk cache or the Falcon API.
+// When force is set, both the current token and the cache are bypassed.
+func (tm *TokenManager) refreshToken(force bool) (string, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
 
-	// Make the request
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("error requesting token: %v", err)
+	if !force {
+		// Double check if token was refreshed by another goroutine
+		if tm.isTokenValid() {
+			return tm.token, nil
+		}
+
+		// Reuse a token minted by an earlier invocation
+		if tm.loadCachedToken(); tm.isTokenValid() {
+			return tm.token, nil
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("error getting token: status code %d", resp.StatusCode)
+	clientID, clientSecret, cloudRegion, err := tm.credentials()
+	if err != nil {
+		return "", err
 	}
 
-	// Parse the response
-	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("error parsing token response: %v", err)
+	tokenResp, err := requestToken(clientID, clientSecret, cloudRegion)
+	if err != nil {
+		return "", err
 	}
 
 	// Update the token manager
 	tm.token = tokenResp.AccessToken
 	tm.expiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn-60) * time.Second) // Subtract 60s for safety margin
 
+	if tm.fixed != nil {
+		return tm.token, nil
+	}
+
+	// A failed cache write only costs a token request on the next run
+	if err := saveCachedToken(clientID, cloudRegion, tm.token, tm.expiresAt); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not cache token: %v\n", err)
+	}
+
 	return tm.token, nil
 }
 
-// GetBearerToken gets a new bearer token from the Falcon API
-func GetBearerToken() (string, error) {
-	clientID := viper.GetString("falcon.client_id")
-	clientSecret := viper.GetString("falcon.client_secret")
-	cloudRegion := viper.GetString("falcon.cloud_region")
+// loadCachedToken replaces the current token with the cached one for the
+// configured credentials, if there is one. Standalone managers have no cache.
+func (tm *TokenManager) loadCachedToken() {
+	if tm.fixed != nil {
+		return
+	}
+	clientID, cloudRegion := tokenCacheKey()
+	cached, err := loadCachedToken(clientID, cloudRegion)
+	if err != nil || cached == nil {
+		return
+	}
+	tm.token = cached.AccessToken
+	tm.expiresAt = cached.ExpiresAt
+}
+
+// tokenCacheKey returns the client ID and cloud region of the active profile,
+// which identify its cached token. They are empty if the profile does not exist.
+func tokenCacheKey() (string, string) {
+	profile, err := ActiveProfile()
+	if err != nil {
+		return "", ""
+	}
+	return profile.ClientID, profile.CloudRegion
+}
 
+// requestToken requests a new OAuth2 token from the Falcon API
+func requestToken(clientID, clientSecret, cloudRegion string) (*TokenResponse, error) {
 	if clientID == "" || clientSecret == "" {
-		return "", fmt.Errorf("Falcon credentials not found. Please run 'falcon-cli init' first")
+		return nil, fmt.Errorf("Falcon credentials not found for profile '%s'. Please run 'falcon-cli init' first", ActiveProfileName())
 	}
 
 	// Get the base URL for the region
 	baseURL, ok := RegionBaseURL[cloudRegion]
 	if !ok {
-		return "", fmt.Errorf("invalid cloud region: %s", cloudRegion)
+		return nil, fmt.Errorf("invalid cloud region: %s", cloudRegion)
 	}
 
-	// Construct the token request URL
-	tokenURL := fmt.Sprintf("%s/oauth2/token", baseURL)
-
-	// Create payload with client credentials
-	payload := strings.NewReader(fmt.Sprintf("client_id=%s&client_secret=%s", clientID, clientSecret))
+	// Create form data with the client credentials
+	formData := url.Values{}
+	formData.Set("client_id", clientID)
+	formData.Set("client_secret", clientSecret)
 
 	// Create the request
-	req, err := http.NewRequest("POST", tokenURL, payload)
+	req, err := http.NewRequest("POST", baseURL+tokenEndpoint, strings.NewReader(formData.Encode()))
 	if err != nil {
-		return "", fmt.Errorf("error creating token request: %v", err)
+		return nil, fmt.Errorf("error creating token request: %v", err)
 	}
 
 	// Add required headers
 	req.Header.Add("accept", "application/json")
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Authorization", "Bearer null")
 
 	// Make the request
-	client := &http.Client{}
+	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("error requesting token: %v", err)
+		return nil, fmt.Errorf("error requesting token: %v", err)
 	}
 	defer resp.Body.Close()
 
 	// Check for successful response (201 Created is expected for token creation)
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("error getting token: status code %d, body: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, "POST", tokenEndpoint)
 	}
 
 	// Parse the response
 	var tokenResp TokenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("error parsing token response: %v", err)
+		return nil, fmt.Errorf("error parsing token response: %v", err)
 	}
 
-	return tokenResp.AccessToken, nil
+	return &tokenResp, nil
+}
+
+// revokeToken revokes a token with the Falcon API
+func revokeToken(clientID, clientSecret, cloudRegion, token string) error {
+	baseURL, ok := RegionBaseURL[cloudRegion]
+	if !ok {
+		return fmt.Errorf("invalid cloud region: %s", cloudRegion)
+	}
+
+	formData := url.Values{}
+	formData.Set("token", token)
+
+	req, err := http.NewRequest("POST", baseURL+revokeEndpoint, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return fmt.Errorf("error creating revoke request: %v", err)
+	}
+
+	req.Header.Add("accept", "application/json")
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(clientID, clientSecret)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error revoking token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp, "POST", revokeEndpoint)
+	}
+
+	return nil
+}
+
+// ValidateCredentials requests a token with the given credentials, to check
+// them before they are saved
+func ValidateCredentials(clientID, clientSecret, cloudRegion string) (*TokenResponse, error) {
+	return requestToken(clientID, clientSecret, cloudRegion)
 }
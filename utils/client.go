@@ -1,38 +1,44 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"time"
-
-	"github.com/spf13/viper"
 )
 
 // FalconClient represents a client for the Falcon API
 type FalconClient struct {
 	BaseURL string
-	Token   string
+	Tokens  *TokenManager
 	Client  *http.Client
+	Retry   RetryPolicy
+
+	// rateLimitedUntil is set when the API reports no remaining requests in the current window
+	rateLimitedUntil time.Time
 }
 
 // NewFalconClient creates a new Falcon API client
 func NewFalconClient() (*FalconClient, error) {
-	// Get cloud region
-	cloudRegion := viper.GetString("falcon.cloud_region")
+	// Get the credentials of the active profile
+	profile, err := ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
 
 	// Get base URL for the region
-	baseURL, ok := RegionBaseURL[cloudRegion]
+	baseURL, ok := RegionBaseURL[profile.CloudRegion]
 	if !ok {
-		return nil, fmt.Errorf("invalid cloud region: %s", cloudRegion)
+		return nil, fmt.Errorf("invalid cloud region: %s", profile.CloudRegion)
 	}
 
-	// Get bearer token
-	token, err := GetBearerToken()
-	if err != nil {
-		return nil, fmt.Errorf("error getting bearer token: %v", err)
+	// Fetch a token up front so credential problems are reported before any request is made
+	tokens := GetTokenManager()
+	if _, err := tokens.GetToken(); err != nil {
+		return nil, fmt.Errorf("error getting bearer token: %w", err)
 	}
 
 	// Create HTTP client with timeout
@@ -42,64 +48,164 @@ func NewFalconClient() (*FalconClient, error) {
 
 	return &FalconClient{
 		BaseURL: baseURL,
-		Token:   token,
+		Tokens:  tokens,
 		Client:  client,
+		Retry:   retryPolicyFromConfig(),
 	}, nil
 }
 
-// Get makes a GET request to the Falcon API
+// Get makes a GET request to the Falcon API. Rate-limited and failed requests are retried.
 func (fc *FalconClient) Get(endpoint string, params map[string]string) (*http.Response, error) {
-	// Build URL with query parameters
-	apiURL := fmt.Sprintf("%s%s", fc.BaseURL, endpoint)
-	if len(params) > 0 {
-		query := url.Values{}
-		for key, value := range params {
-			query.Add(key, value)
-		}
-		apiURL = fmt.Sprintf("%s?%s", apiURL, query.Encode())
+	query := url.Values{}
+	for key, value := range params {
+		query.Add(key, value)
 	}
+	return fc.do("GET", endpoint, query, nil, true)
+}
 
-	// Create request
-	req, err := http.NewRequest("GET", apiURL, nil)
+// GetEntities makes a GET request for entities by ID, sending each ID as a
+// separate ids query parameter. It is retried like Get.
+func (fc *FalconClient) GetEntities(endpoint string, ids []string) (*http.Response, error) {
+	return fc.do("GET", endpoint, url.Values{"ids": ids}, nil, true)
+}
+
+// Delete makes a DELETE request for entities by ID, sending each ID as a
+// separate ids query parameter. Like Post, it is not retried.
+func (fc *FalconClient) Delete(endpoint string, ids []string) (*http.Response, error) {
+	return fc.do("DELETE", endpoint, url.Values{"ids": ids}, nil, false)
+}
+
+// Post makes a POST request to the Falcon API. It is not retried, since POSTs
+// may not be safe to repeat; use PostWithRetry for POSTs that only read data.
+func (fc *FalconClient) Post(endpoint string, body io.Reader) (*http.Response, error) {
+	return fc.post(endpoint, body, false)
+}
+
+// PostWithRetry makes a POST request that is retried like a GET. Only use it for
+// idempotent requests, such as entity lookups.
+func (fc *FalconClient) PostWithRetry(endpoint string, body io.Reader) (*http.Response, error) {
+	return fc.post(endpoint, body, true)
+}
+
+// PostWithParams makes a POST request with query parameters, such as the
+// action_name of an action endpoint. Like Post, it is not retried.
+func (fc *FalconClient) PostWithParams(endpoint string, params map[string]string, body io.Reader) (*http.Response, error) {
+	query := url.Values{}
+	for key, value := range params {
+		query.Add(key, value)
+	}
+	payload, err := io.ReadAll(body)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
+		return nil, fmt.Errorf("error reading request body: %v", err)
 	}
+	return fc.do("POST", endpoint, query, payload, false)
+}
 
-	// Add headers
-	req.Header.Add("accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+fc.Token)
-
-	// Make request
-	resp, err := fc.Client.Do(req)
+func (fc *FalconClient) post(endpoint string, body io.Reader, retry bool) (*http.Response, error) {
+	// Buffer the body so the request can be replayed after a token refresh or retry
+	payload, err := io.ReadAll(body)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error reading request body: %v", err)
 	}
+	return fc.do("POST", endpoint, nil, payload, retry)
+}
 
-	// Check for successful response
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("error: status code %d, body: %s", resp.StatusCode, string(body))
+// Patch makes a PATCH request to the Falcon API. Like Post, it is not retried.
+func (fc *FalconClient) Patch(endpoint string, body io.Reader) (*http.Response, error) {
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading request body: %v", err)
 	}
+	return fc.do("PATCH", endpoint, nil, payload, false)
+}
 
-	return resp, nil
+// do sends a request with a bearer token from the token manager. If the API
+// rejects the token with a 401, the token is refreshed and the request is
+// retried once. When retry is set, rate-limited (429) responses, server errors
+// and network failures are retried with exponential backoff.
+func (fc *FalconClient) do(method, endpoint string, query url.Values, payload []byte, retry bool) (*http.Response, error) {
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		// Wait out a rate limit window reported by an earlier response
+		if wait := time.Until(fc.rateLimitedUntil); wait > 0 {
+			logf("Rate limit reached, waiting %s before %s %s", wait.Round(time.Millisecond), method, endpoint)
+			time.Sleep(wait)
+		}
+
+		token, err := fc.Tokens.GetToken()
+		if err != nil {
+			return nil, fmt.Errorf("error getting bearer token: %w", err)
+		}
+
+		canRetry := retry && attempt < fc.Retry.MaxRetries
+
+		resp, err := fc.send(method, endpoint, query, payload, token)
+		if err != nil {
+			if !canRetry {
+				return nil, err
+			}
+			delay := fc.Retry.backoff(attempt + 1)
+			logf("Retrying %s %s in %s (attempt %d of %d): %v", method, endpoint, delay.Round(time.Millisecond), attempt+1, fc.Retry.MaxRetries, err)
+			time.Sleep(delay)
+			continue
+		}
+
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			fc.rateLimitedUntil = rateLimitReset(resp.Header)
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && !refreshed {
+			resp.Body.Close()
+			fc.Tokens.Invalidate(token)
+			refreshed = true
+			attempt--
+			continue
+		}
+
+		if isRetryableStatus(resp.StatusCode) && canRetry {
+			resp.Body.Close()
+			delay := fc.Retry.backoff(attempt + 1)
+			if wait := time.Until(rateLimitReset(resp.Header)); wait > delay {
+				delay = wait
+			}
+			logf("Retrying %s %s in %s (attempt %d of %d): status code %d", method, endpoint, delay.Round(time.Millisecond), attempt+1, fc.Retry.MaxRetries, resp.StatusCode)
+			time.Sleep(delay)
+			continue
+		}
+
+		// Check for successful response
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, newAPIError(resp, method, endpoint)
+		}
+
+		return resp, nil
+	}
 }
 
-// Post makes a POST request to the Falcon API
-func (fc *FalconClient) Post(endpoint string, body io.Reader) (*http.Response, error) {
-	// Build URL
+// send makes a single request with the given bearer token
+func (fc *FalconClient) send(method, endpoint string, query url.Values, payload []byte, token string) (*http.Response, error) {
+	// Build URL with query parameters
 	apiURL := fmt.Sprintf("%s%s", fc.BaseURL, endpoint)
+	if len(query) > 0 {
+		apiURL = fmt.Sprintf("%s?%s", apiURL, query.Encode())
+	}
 
 	// Create request
-	req, err := http.NewRequest("POST", apiURL, body)
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, apiURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
 	// Add headers
 	req.Header.Add("accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", "Bearer "+fc.Token)
+	if payload != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	req.Header.Add("Authorization", "Bearer "+token)
 
 	// Make request
 	resp, err := fc.Client.Do(req)
@@ -107,13 +213,6 @@ func (fc *FalconClient) Post(endpoint string, body io.Reader) (*http.Response, e
 		return nil, fmt.Errorf("error making request: %v", err)
 	}
 
-	// Check for successful response
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("error: status code %d, body: %s", resp.StatusCode, string(body))
-	}
-
 	return resp, nil
 }
 
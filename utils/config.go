@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// ConfigPath returns the config file in use, or the default location if none was found
+func ConfigPath() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home directory: %v", err)
+	}
+	return filepath.Join(home, ".falcon-cli", "config.yaml"), nil
+}
+
+// RewriteConfig applies update to the settings stored in the config file and
+// writes the result back. Unlike viper.Set, this can remove keys. The global
+// viper instance is reloaded afterwards.
+func RewriteConfig(update func(settings map[string]interface{}) error) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	current := viper.New()
+	current.SetConfigFile(path)
+	if err := current.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading config file: %v", err)
+	}
+
+	settings := current.AllSettings()
+	if err := update(settings); err != nil {
+		return err
+	}
+
+	updated := viper.New()
+	updated.SetConfigPermissions(0600)
+	if err := updated.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("error updating config: %v", err)
+	}
+	if err := updated.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("error writing config file: %v", err)
+	}
+
+	viper.SetConfigFile(path)
+	return viper.ReadInConfig()
+}
+
+// SecureConfigFile restricts the config file to its owner, tightening
+// permissions left by older versions. The directory is only restricted when it
+// is the CLI's own ~/.falcon-cli, so a config given with --config never changes
+// the permissions of the directory it lives in.
+func SecureConfigFile(path string) error {
+	if ownConfigDir(filepath.Dir(path)) {
+		if info, err := os.Stat(filepath.Dir(path)); err == nil && info.Mode().Perm()&0077 != 0 {
+			if err := os.Chmod(filepath.Dir(path), 0700); err != nil {
+				return fmt.Errorf("error securing config directory: %v", err)
+			}
+		}
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0077 != 0 {
+		if err := os.Chmod(path, 0600); err != nil {
+			return fmt.Errorf("error securing config file: %v", err)
+		}
+	}
+	return nil
+}
+
+// ownConfigDir reports whether dir is ~/.falcon-cli
+func ownConfigDir(dir string) bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	return abs == filepath.Join(home, ".falcon-cli")
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecureConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// writeConfig creates a world-readable config file in dir
+	writeConfig := func(dir string) string {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("MkdirAll returned error: %v", err)
+		}
+		os.Chmod(dir, 0755)
+		path := filepath.Join(dir, "config.yaml")
+		if err := os.WriteFile(path, []byte("falcon: {}\n"), 0644); err != nil {
+			t.Fatalf("WriteFile returned error: %v", err)
+		}
+		return path
+	}
+	mode := func(path string) os.FileMode {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat returned error: %v", err)
+		}
+		return info.Mode().Perm()
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		dirMode os.FileMode
+	}{
+		{name: "own config directory", dir: filepath.Join(home, ".falcon-cli"), dirMode: 0700},
+		{name: "config given with --config", dir: filepath.Join(home, "project"), dirMode: 0755},
+		{name: "directory named like the own one", dir: filepath.Join(home, "other", ".falcon-cli"), dirMode: 0755},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(tt.dir)
+			if err := SecureConfigFile(path); err != nil {
+				t.Fatalf("SecureConfigFile returned error: %v", err)
+			}
+			if got := mode(path); got != 0600 {
+				t.Errorf("config file mode = %o, want 600", got)
+			}
+			if got := mode(tt.dir); got != tt.dirMode {
+				t.Errorf("config directory mode = %o, want %o", got, tt.dirMode)
+			}
+		})
+	}
+}
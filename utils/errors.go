@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Process exit codes, so automation can branch on the kind of failure
+const (
+	ExitOK          = 0
+	ExitError       = 1 // Any other failure, including usage errors
+	ExitAuth        = 3 // Credentials were rejected
+	ExitForbidden   = 4 // The API client lacks the scope for the request
+	ExitNotFound    = 5 // The requested resource does not exist
+	ExitRateLimited = 6 // Still rate-limited after all retries
+	ExitServer      = 7 // The API returned a server error
+)
+
+// APIErrorDetail is a single entry of the errors array in a Falcon response
+type APIErrorDetail struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// APIError is returned when the Falcon API responds with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	Errors     []APIErrorDetail
+	TraceID    string
+	Body       string // Raw response body, kept when it is not a Falcon error document
+}
+
+// newAPIError builds an APIError from a failed response, consuming its body
+func newAPIError(resp *http.Response, method, endpoint string) *APIError {
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     method,
+		Endpoint:   endpoint,
+	}
+
+	var doc struct {
+		Errors []APIErrorDetail `json:"errors"`
+		Meta   struct {
+			TraceID string `json:"trace_id"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(body, &doc); err == nil && (len(doc.Errors) > 0 || doc.Meta.TraceID != "") {
+		apiErr.Errors = doc.Errors
+		apiErr.TraceID = doc.Meta.TraceID
+	} else {
+		apiErr.Body = strings.TrimSpace(string(body))
+	}
+	if apiErr.TraceID == "" {
+		apiErr.TraceID = resp.Header.Get("X-Cs-Traceid")
+	}
+
+	return apiErr
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+
+	var messages []string
+	for _, detail := range e.Errors {
+		messages = append(messages, detail.Message)
+	}
+	if len(messages) > 0 {
+		fmt.Fprintf(&b, ": %s", strings.Join(messages, "; "))
+	} else if e.Body != "" {
+		fmt.Fprintf(&b, ": %s", e.Body)
+	}
+
+	if e.StatusCode == http.StatusForbidden {
+		if scope := e.MissingScope(); scope != "" {
+			fmt.Fprintf(&b, " (the API client needs the '%s' scope)", scope)
+		}
+	}
+	if e.TraceID != "" {
+		fmt.Fprintf(&b, " [trace_id: %s]", e.TraceID)
+	}
+	return b.String()
+}
+
+// ExitCode returns the process exit code for the error
+func (e *APIError) ExitCode() int {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ExitAuth
+	case e.StatusCode == http.StatusForbidden && e.Endpoint == tokenEndpoint:
+		// The token endpoint answers 403 for invalid client credentials
+		return ExitAuth
+	case e.StatusCode == http.StatusForbidden:
+		return ExitForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ExitNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ExitRateLimited
+	case e.StatusCode >= 500:
+		return ExitServer
+	}
+	return ExitError
+}
+
+// apiScopes maps endpoint prefixes to the API scope that grants access to them.
+// More specific prefixes come first.
+var apiScopes = []struct {
+	prefix string
+	scope  string
+}{
+	{"/devices/entities/host-group", "Host groups"},
+	{"/devices/queries/host-group", "Host groups"},
+	{"/devices/combined/host-group", "Host groups"},
+	{"/devices/", "Hosts"},
+	{"/alerts/", "Alerts"},
+	{"/incidents/", "Incidents"},
+	{"/detects/", "Detections"},
+}
+
+// readOnlyPosts are POST endpoints that only read data and so need read scope
+var readOnlyPosts = map[string]bool{
+	"/devices/entities/devices/v2": true,
+	"/alerts/entities/alerts/v2":   true,
+}
+
+// MissingScope returns the API scope required by the failed request, such as
+// "Hosts: Read", or an empty string if the endpoint is not known
+func (e *APIError) MissingScope() string {
+	for _, s := range apiScopes {
+		if !strings.HasPrefix(e.Endpoint, s.prefix) {
+			continue
+		}
+		access := "Write"
+		if e.Method == "GET" || readOnlyPosts[e.Endpoint] || strings.Contains(e.Endpoint, "/GET/") {
+			access = "Read"
+		}
+		return fmt.Sprintf("%s: %s", s.scope, access)
+	}
+	return ""
+}
+
+// ExitCode returns the process exit code for an error returned by a command
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ExitCode()
+	}
+	return ExitError
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "no error", err: nil, want: ExitOK},
+		{name: "other error", err: errors.New("boom"), want: ExitError},
+		{name: "unauthorized", err: &APIError{StatusCode: 401, Method: "GET", Endpoint: "/devices/queries/devices/v1"}, want: ExitAuth},
+		{name: "token endpoint forbidden", err: &APIError{StatusCode: 403, Method: "POST", Endpoint: tokenEndpoint}, want: ExitAuth},
+		{name: "forbidden", err: &APIError{StatusCode: 403, Method: "GET", Endpoint: "/devices/queries/devices/v1"}, want: ExitForbidden},
+		{name: "not found", err: &APIError{StatusCode: 404, Method: "GET", Endpoint: "/devices/entities/devices/v2"}, want: ExitNotFound},
+		{name: "rate limited", err: &APIError{StatusCode: 429, Method: "GET", Endpoint: "/alerts/queries/alerts/v2"}, want: ExitRateLimited},
+		{name: "internal server error", err: &APIError{StatusCode: 500, Method: "GET", Endpoint: "/alerts/queries/alerts/v2"}, want: ExitServer},
+		{name: "service unavailable", err: &APIError{StatusCode: 503, Method: "GET", Endpoint: "/alerts/queries/alerts/v2"}, want: ExitServer},
+		{name: "bad request", err: &APIError{StatusCode: 400, Method: "GET", Endpoint: "/alerts/queries/alerts/v2"}, want: ExitError},
+		{name: "conflict", err: &APIError{StatusCode: 409, Method: "POST", Endpoint: "/devices/entities/host-groups/v1"}, want: ExitError},
+		{name: "wrapped", err: fmt.Errorf("error getting hosts: %w", &APIError{StatusCode: 403, Method: "GET", Endpoint: "/devices/queries/devices/v1"}), want: ExitForbidden},
+		{name: "wrapped twice", err: fmt.Errorf("error creating Falcon client: %w", fmt.Errorf("error getting bearer token: %w", &APIError{StatusCode: 403, Method: "POST", Endpoint: tokenEndpoint})), want: ExitAuth},
+		{name: "wrapped with %v", err: fmt.Errorf("error getting hosts: %v", &APIError{StatusCode: 404}), want: ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorFromResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		want     int
+		wantText string
+	}{
+		{name: "unauthorized", status: 401, body: `{"errors":[{"code":401,"message":"access denied, invalid bearer token"}]}`, want: ExitAuth, wantText: "401 Unauthorized: access denied, invalid bearer token"},
+		{name: "forbidden", status: 403, body: `{"errors":[{"code":403,"message":"access denied, authorization failed"}],"meta":{"trace_id":"abc"}}`, want: ExitForbidden, wantText: "(the API client needs the 'Hosts: Read' scope) [trace_id: abc]"},
+		{name: "not found", status: 404, body: `{"errors":[{"code":404,"message":"not found"}]}`, want: ExitNotFound, wantText: "404 Not Found: not found"},
+		{name: "rate limited", status: 429, body: `{"errors":[{"code":429,"message":"API rate limit exceeded."}]}`, want: ExitRateLimited, wantText: "429 Too Many Requests"},
+		{name: "server error with plain body", status: 502, body: "bad gateway\n", want: ExitServer, wantText: "502 Bad Gateway: bad gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			// A 401 makes the client refresh its token from the same server, which rejects it again
+			fc := newTestClient(srv)
+			fc.Tokens = NewStandaloneTokenManager("id", "secret", "test", &TokenResponse{AccessToken: "t", ExpiresIn: 3600})
+			RegionBaseURL["test"] = srv.URL
+			defer delete(RegionBaseURL, "test")
+
+			_, err := fc.Get("/devices/queries/devices/v1", nil)
+			err = fmt.Errorf("error getting hosts: %w", err)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error %v does not wrap an APIError", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.status)
+			}
+			if got := ExitCode(err); got != tt.want {
+				t.Errorf("ExitCode = %d, want %d", got, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.wantText) {
+				t.Errorf("error = %q, want it to contain %q", err.Error(), tt.wantText)
+			}
+		})
+	}
+}
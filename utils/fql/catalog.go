@@ -0,0 +1,195 @@
+package fql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// catalog lists the filterable fields of each filter type. Entries ending in
+// ".*" accept any field under that prefix, such as device.hostname.
+var catalog = map[string][]string{
+	"hosts": {
+		"agent_load_flags", "agent_local_time", "agent_version", "bios_manufacturer", "bios_version",
+		"chassis_type", "chassis_type_desc", "cid", "config_id_base", "config_id_build", "config_id_platform",
+		"connection_ip", "connection_mac_address", "cpu_signature", "default_gateway_ip", "deployment_type",
+		"device_id", "device_policies.*", "external_ip", "first_seen", "groups", "host_hidden_status",
+		"hostname", "instance_id", "k8s_cluster_id", "kernel_version", "last_login_timestamp",
+		"last_login_user", "last_reboot", "last_seen", "linux_sensor_mode", "local_ip", "mac_address",
+		"machine_domain", "major_version", "minor_version", "modified_timestamp", "os_build",
+		"os_product_name", "os_version", "ou", "platform_id", "platform_name", "pod_name", "pod_namespace",
+		"policies.*", "product_type", "product_type_desc", "reduced_functionality_mode", "release_group",
+		"serial_number", "service_provider", "service_provider_account_id", "site_name", "status",
+		"system_manufacturer", "system_product_name", "tags", "zone_group",
+	},
+	"detections": {
+		"adversary_ids", "assigned_to_name", "assigned_to_uid", "behaviors.*", "cid", "date_updated",
+		"detection_id", "device.*", "email_sent", "first_behavior", "hostinfo.*", "last_behavior",
+		"max_confidence", "max_severity", "max_severity_displayname", "quarantined_files.*",
+		"seconds_to_resolved", "seconds_to_triaged", "show_in_ui", "status",
+	},
+	"alerts": {
+		"aggregate_id", "assigned_to_name", "assigned_to_uid", "assigned_to_uuid", "cid", "cmdline",
+		"composite_id", "confidence", "crawled_timestamp", "created_timestamp", "description", "device.*",
+		"display_name", "email_sent", "falcon_host_link", "filename", "filepath", "id", "md5", "name",
+		"objective", "parent_details.*", "pattern_id", "platform", "product", "scenario",
+		"seconds_to_resolved", "seconds_to_triaged", "severity", "severity_name", "sha256", "show_in_ui",
+		"source_products", "source_vendors", "status", "tactic", "tactic_id", "tags", "technique",
+		"technique_id", "timestamp", "type", "updated_timestamp", "user_name",
+	},
+	"incidents": {
+		"assigned_to", "assigned_to_name", "cid", "created", "description", "end", "fine_score",
+		"host_ids", "hosts.*", "incident_id", "lm_host_ids", "modified_timestamp", "name", "objectives",
+		"start", "state", "status", "tactics", "tags", "techniques", "users",
+	},
+}
+
+func init() {
+	// Host group assignment rules select hosts, so they are checked against the hosts fields
+	catalog["host-groups"] = catalog["hosts"]
+	enumValues["host-groups"] = enumValues["hosts"]
+}
+
+// enumValues lists the known values of fields that take a fixed set of values
+var enumValues = map[string]map[string][]string{
+	"hosts": {
+		"platform_name":              {"Windows", "Mac", "Linux"},
+		"status":                     {"normal", "containment_pending", "contained", "lift_containment_pending"},
+		"product_type_desc":          {"Workstation", "Server", "Domain Controller"},
+		"reduced_functionality_mode": {"yes", "no", "Unknown"},
+		"host_hidden_status":         {"visible", "hidden"},
+	},
+	"detections": {
+		"status":                   {"new", "in_progress", "true_positive", "false_positive", "ignored", "closed", "reopened"},
+		"max_severity_displayname": {"Informational", "Low", "Medium", "High", "Critical"},
+	},
+	"alerts": {
+		"status":        {"new", "in_progress", "closed", "reopened"},
+		"severity_name": {"Informational", "Low", "Medium", "High", "Critical"},
+	},
+	"incidents": {
+		"state":  {"open", "closed"},
+		"status": {"20", "25", "30", "40"},
+	},
+}
+
+// Values returns the known values of an enumerated field, or nil if the field
+// takes free-form values
+func Values(filterType, field string) []string {
+	return enumValues[filterType][field]
+}
+
+// Types returns the filter types that have a field catalog
+func Types() []string {
+	types := make([]string, 0, len(catalog))
+	for t := range catalog {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Fields returns the filterable fields of a filter type, or nil if the type has no catalog
+func Fields(filterType string) []string {
+	return catalog[filterType]
+}
+
+// knownField reports whether field is in the catalog of a filter type
+func knownField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(f, "*"); ok && strings.HasPrefix(field, prefix) && len(field) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns the catalog field closest to field, or "" if none is close
+func suggest(fields []string, field string) string {
+	best, bestDistance := "", len(field)/2+2
+	for _, f := range fields {
+		candidate := strings.TrimSuffix(f, "*")
+		if d := levenshtein(field, candidate); d < bestDistance {
+			best, bestDistance = f, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// Validate parses a filter expression and checks its field names against the
+// catalog for filterType. Types without a catalog are only checked for syntax.
+func Validate(input, filterType string) (Node, error) {
+	node, err := Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, ok := catalog[filterType]
+	if !ok {
+		return node, nil
+	}
+
+	var verr error
+	Walk(node, func(c *Comparison) bool {
+		if knownField(fields, c.Field) {
+			return true
+		}
+		msg := fmt.Sprintf("unknown field '%s' for type '%s'", c.Field, filterType)
+		if s := suggest(fields, c.Field); s != "" {
+			msg += fmt.Sprintf(" (did you mean '%s'?)", s)
+		}
+		verr = &SyntaxError{Input: input, Offset: c.pos, Msg: msg}
+		return false
+	})
+	if verr != nil {
+		return nil, verr
+	}
+	return node, nil
+}
+
+// Walk calls fn for each comparison in node, in order, until fn returns false
+func Walk(node Node, fn func(*Comparison) bool) bool {
+	switch n := node.(type) {
+	case *And:
+		for _, t := range n.Terms {
+			if !Walk(t, fn) {
+				return false
+			}
+		}
+	case *Or:
+		for _, t := range n.Terms {
+			if !Walk(t, fn) {
+				return false
+			}
+		}
+	case *Not:
+		return Walk(n.X, fn)
+	case *Comparison:
+		return fn(n)
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package fql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input      string
+		filterType string
+		msg        string // Expected error message prefix; empty when valid
+	}{
+		{input: "platform_name:'Windows'+hostname:'nowak-pc'", filterType: "hosts"},
+		{input: "device_policies.prevention.applied:true", filterType: "hosts"},
+		{input: "last_seen:<='now-30d'", filterType: "host-groups"},
+		{input: "anything:'x'", filterType: "custom"},
+		{input: "platfrom_name:'Windows'", filterType: "hosts", msg: "unknown field 'platfrom_name' for type 'hosts' (did you mean 'platform_name'?)"},
+		{input: "device_policies.:'x'", filterType: "hosts", msg: "unknown field 'device_policies.'"},
+		{input: "status:'new'+zzzzzzzzzz:1", filterType: "alerts", msg: "unknown field 'zzzzzzzzzz' for type 'alerts'"},
+		{input: "hostname:", filterType: "hosts", msg: "unexpected end of filter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Validate(tt.input, tt.filterType)
+			if tt.msg == "" {
+				if err != nil {
+					t.Fatalf("Validate(%q, %q) returned error: %v", tt.input, tt.filterType, err)
+				}
+				return
+			}
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Validate(%q, %q) error = %v, want a SyntaxError", tt.input, tt.filterType, err)
+			}
+			if !strings.HasPrefix(serr.Msg, tt.msg) {
+				t.Errorf("Validate(%q, %q) error = %q, want %q", tt.input, tt.filterType, serr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestValidateErrorPosition(t *testing.T) {
+	_, err := Validate("hostname:'a'+bogus:1", "hosts")
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Fatalf("Validate error = %v, want a SyntaxError", err)
+	}
+	if got := serr.Column(); got != 14 {
+		t.Errorf("error column = %d, want 14", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "abc", want: 3},
+		{a: "status", b: "status", want: 0},
+		{a: "hostnme", b: "hostname", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package fql
+
+// negatedOperators maps each comparison operator to its opposite
+var negatedOperators = map[string]string{
+	"":   "!",
+	"!":  "",
+	">":  "<=",
+	">=": "<",
+	"<":  ">=",
+	"<=": ">",
+	"~":  "!~",
+	"!~": "~",
+	"*":  "!*",
+	"!*": "*",
+}
+
+// AndOf combines nodes so that all of them must match, flattening nested ANDs
+func AndOf(nodes ...Node) Node {
+	var terms []Node
+	for _, n := range nodes {
+		if and, ok := n.(*And); ok {
+			terms = append(terms, and.Terms...)
+		} else {
+			terms = append(terms, n)
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return &And{Terms: terms}
+}
+
+// OrOf combines nodes so that any of them must match, flattening nested ORs
+func OrOf(nodes ...Node) Node {
+	var terms []Node
+	for _, n := range nodes {
+		if or, ok := n.(*Or); ok {
+			terms = append(terms, or.Terms...)
+		} else {
+			terms = append(terms, n)
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return &Or{Terms: terms}
+}
+
+// Negate returns the negation of node. The API cannot negate a group, so the
+// negation is pushed down to the comparisons using De Morgan's laws:
+// !(a+b) becomes !a,!b and field:>5 becomes field:<=5.
+func Negate(node Node) Node {
+	switch n := node.(type) {
+	case *And:
+		terms := make([]Node, len(n.Terms))
+		for i, t := range n.Terms {
+			terms[i] = Negate(t)
+		}
+		return OrOf(terms...)
+	case *Or:
+		terms := make([]Node, len(n.Terms))
+		for i, t := range n.Terms {
+			terms[i] = Negate(t)
+		}
+		return AndOf(terms...)
+	case *Not:
+		return n.X
+	case *Comparison:
+		negated := *n
+		negated.Operator = negatedOperators[n.Operator]
+		return &negated
+	}
+	return &Not{X: node}
+}
@@ -0,0 +1,43 @@
+package fql
+
+import "testing"
+
+func TestNegate(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{input: "a:1", want: "a:!1"},
+		{input: "a:>5", want: "a:<=5"},
+		{input: "a:1+b:~'x'", want: "a:!1,b:!~'x'"},
+		{input: "(a:1,b:2)+c:*'w*'", want: "(a:!1+b:!2),c:!*'w*'"},
+		{input: "!a:1", want: "a:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			if got := Negate(node).String(); got != tt.want {
+				t.Errorf("Negate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAndOfOrOf(t *testing.T) {
+	a, _ := Parse("a:1+b:2")
+	c, _ := Parse("c:3")
+	d, _ := Parse("d:4,e:5")
+
+	if got := AndOf(a, c, d).String(); got != "a:1+b:2+c:3+(d:4,e:5)" {
+		t.Errorf("AndOf = %q", got)
+	}
+	if got := OrOf(d, c).String(); got != "d:4,e:5,c:3" {
+		t.Errorf("OrOf = %q", got)
+	}
+	if got := AndOf(c); got != c {
+		t.Errorf("AndOf of one node = %v, want the node itself", got)
+	}
+}
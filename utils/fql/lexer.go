@@ -0,0 +1,180 @@
+package fql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind identifies the kind of a lexical token
+type tokenKind int
+
+const (
+	tokenEOF      tokenKind = iota
+	tokenWord               // Field names and unquoted values: hostname, 42, true, now-7d
+	tokenString             // Quoted values: 'Windows', "web*"
+	tokenColon              // :
+	tokenAnd                // +
+	tokenOr                 // ,
+	tokenNot                // !
+	tokenLParen             // (
+	tokenRParen             // )
+	tokenLBracket           // [
+	tokenRBracket           // ]
+	tokenOperator           // >, >=, <, <=, ~, !~, *, !*
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of filter"
+	case tokenWord:
+		return "word"
+	case tokenString:
+		return "quoted string"
+	case tokenColon:
+		return "':'"
+	case tokenAnd:
+		return "'+'"
+	case tokenOr:
+		return "','"
+	case tokenNot:
+		return "'!'"
+	case tokenLParen:
+		return "'('"
+	case tokenRParen:
+		return "')'"
+	case tokenLBracket:
+		return "'['"
+	case tokenRBracket:
+		return "']'"
+	case tokenOperator:
+		return "operator"
+	}
+	return "token"
+}
+
+// token is a lexical token with its byte offset in the input
+type token struct {
+	kind  tokenKind
+	text  string // Unquoted text for strings, the literal text otherwise
+	raw   string // The token as written
+	start int
+}
+
+// SyntaxError reports a problem at a position in a filter expression
+type SyntaxError struct {
+	Input  string
+	Offset int // Byte offset of the problem
+	Msg    string
+}
+
+// Column returns the 1-based column of the problem
+func (e *SyntaxError) Column() int {
+	if e.Offset > len(e.Input) {
+		return utf8.RuneCountInString(e.Input) + 1
+	}
+	return utf8.RuneCountInString(e.Input[:e.Offset]) + 1
+}
+
+// Error implements the error interface, pointing at the problem with a caret
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid filter at column %d: %s\n  %s\n  %s^",
+		e.Column(), e.Msg, e.Input, strings.Repeat(" ", e.Column()-1))
+}
+
+// isWordChar reports whether c can appear in a field name or unquoted value;
+// '/' is allowed for rounded date math such as now-1d/d
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '-' || c == '/'
+}
+
+// lex splits a filter expression into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '\'' || c == '"':
+			text, end, err := lexString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			tokens = append(tokens, token{kind: tokenString, text: text, raw: input[start:i], start: start})
+			continue
+		case isWordChar(c):
+			for i < len(input) && isWordChar(input[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: input[start:i], raw: input[start:i], start: start})
+			continue
+		}
+
+		kind := tokenOperator
+		width := 1
+		switch c {
+		case ':':
+			kind = tokenColon
+		case '+':
+			kind = tokenAnd
+		case ',':
+			kind = tokenOr
+		case '(':
+			kind = tokenLParen
+		case ')':
+			kind = tokenRParen
+		case '[':
+			kind = tokenLBracket
+		case ']':
+			kind = tokenRBracket
+		case '!':
+			kind = tokenNot
+			if i+1 < len(input) && (input[i+1] == '~' || input[i+1] == '*') {
+				kind, width = tokenOperator, 2
+			}
+		case '>', '<':
+			if i+1 < len(input) && input[i+1] == '=' {
+				width = 2
+			}
+		case '~', '*':
+		default:
+			r, _ := utf8.DecodeRuneInString(input[i:])
+			return nil, &SyntaxError{Input: input, Offset: i, Msg: fmt.Sprintf("unexpected character '%c'", r)}
+		}
+
+		i += width
+		tokens = append(tokens, token{kind: kind, text: input[start:i], raw: input[start:i], start: start})
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, start: len(input)})
+	return tokens, nil
+}
+
+// lexString reads a quoted string starting at input[start], returning its
+// unquoted text and the offset just past the closing quote. A backslash
+// escapes the next character.
+func lexString(input string, start int) (string, int, error) {
+	quote := input[start]
+	var b strings.Builder
+	for i := start + 1; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == '\\' && i+1 < len(input):
+			i++
+			b.WriteByte(input[i])
+		case c == quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, &SyntaxError{Input: input, Offset: start, Msg: "unterminated string"}
+}
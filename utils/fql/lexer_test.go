@@ -0,0 +1,98 @@
+package fql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		input string
+		kinds []tokenKind
+		texts []string
+	}{
+		{
+			input: "platform_name:'Windows'",
+			kinds: []tokenKind{tokenWord, tokenColon, tokenString, tokenEOF},
+			texts: []string{"platform_name", ":", "Windows", ""},
+		},
+		{
+			input: `hostname:"web*"+status:!'contained'`,
+			kinds: []tokenKind{tokenWord, tokenColon, tokenString, tokenAnd, tokenWord, tokenColon, tokenNot, tokenString, tokenEOF},
+			texts: []string{"hostname", ":", "web*", "+", "status", ":", "!", "contained", ""},
+		},
+		{
+			input: "last_seen:>=now-7d/d",
+			kinds: []tokenKind{tokenWord, tokenColon, tokenOperator, tokenWord, tokenEOF},
+			texts: []string{"last_seen", ":", ">=", "now-7d/d", ""},
+		},
+		{
+			input: "hostname:!~'web' , name:!*'x'",
+			kinds: []tokenKind{tokenWord, tokenColon, tokenOperator, tokenString, tokenOr, tokenWord, tokenColon, tokenOperator, tokenString, tokenEOF},
+			texts: []string{"hostname", ":", "!~", "web", ",", "name", ":", "!*", "x", ""},
+		},
+		{
+			input: "(a:['x',2])",
+			kinds: []tokenKind{tokenLParen, tokenWord, tokenColon, tokenLBracket, tokenString, tokenOr, tokenWord, tokenRBracket, tokenRParen, tokenEOF},
+			texts: []string{"(", "a", ":", "[", "x", ",", "2", "]", ")", ""},
+		},
+		{
+			input: `name:'it\'s'`,
+			kinds: []tokenKind{tokenWord, tokenColon, tokenString, tokenEOF},
+			texts: []string{"name", ":", "it's", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := lex(tt.input)
+			if err != nil {
+				t.Fatalf("lex(%q) returned error: %v", tt.input, err)
+			}
+			if len(tokens) != len(tt.kinds) {
+				t.Fatalf("lex(%q) returned %d tokens, want %d", tt.input, len(tokens), len(tt.kinds))
+			}
+			for i, tok := range tokens {
+				if tok.kind != tt.kinds[i] || tok.text != tt.texts[i] {
+					t.Errorf("token %d = %v %q, want %v %q", i, tok.kind, tok.text, tt.kinds[i], tt.texts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLexErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+		msg    string
+	}{
+		{input: "hostname:'web", offset: 9, msg: "unterminated string"},
+		{input: "hostname:web;", offset: 12, msg: "unexpected character ';'"},
+		{input: "a:1+b:é", offset: 6, msg: "unexpected character 'é'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := lex(tt.input)
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("lex(%q) error = %v, want a SyntaxError", tt.input, err)
+			}
+			if serr.Offset != tt.offset || serr.Msg != tt.msg {
+				t.Errorf("lex(%q) error at %d %q, want at %d %q", tt.input, serr.Offset, serr.Msg, tt.offset, tt.msg)
+			}
+		})
+	}
+}
+
+func TestSyntaxErrorColumn(t *testing.T) {
+	err := &SyntaxError{Input: "name:'é'+x", Offset: 9, Msg: "test"}
+	if got := err.Column(); got != 9 {
+		t.Errorf("Column() = %d, want 9", got)
+	}
+	err.Offset = 100
+	if got := err.Column(); got != 11 {
+		t.Errorf("Column() past the end = %d, want 11", got)
+	}
+}
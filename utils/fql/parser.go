@@ -0,0 +1,380 @@
+// Package fql parses and validates Falcon Query Language (FQL) filter expressions.
+//
+// The supported syntax is the one documented in docs/filters.md:
+//
+//	field:'value'                  equality
+//	field:!'value'  or  !field:'value'   negation
+//	field:>10  field:>='now-7d'    comparisons, with date math
+//	field:*'web*'  field:~'web'    wildcard and text match
+//	field:['a','b']                any of several values
+//	a+b  a,b  (a,b)+c              AND, OR and grouping
+package fql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValueKind identifies the kind of a comparison value
+type ValueKind int
+
+const (
+	StringValue   ValueKind = iota // A quoted string
+	NumberValue                    // An unquoted number
+	BoolValue                      // true or false
+	NullValue                      // null
+	WordValue                      // Any other unquoted word
+	DateMathValue                  // now, optionally with offsets such as now-7d
+	ListValue                      // [value, value, ...]
+)
+
+// Node is a node of a parsed filter expression
+type Node interface {
+	// Pos returns the byte offset of the node in the parsed input
+	Pos() int
+	// String renders the node as FQL
+	String() string
+}
+
+// And matches when every term matches
+type And struct {
+	Terms []Node
+	pos   int
+}
+
+// Or matches when any term matches
+type Or struct {
+	Terms []Node
+	pos   int
+}
+
+// Not matches when X does not match
+type Not struct {
+	X   Node
+	pos int
+}
+
+// Comparison compares a field with a value, such as platform_name:'Windows'
+type Comparison struct {
+	Field    string
+	Operator string // "", "!", ">", ">=", "<", "<=", "~", "!~", "*" or "!*"
+	Value    Value
+	pos      int
+}
+
+// Value is the right-hand side of a comparison
+type Value struct {
+	Kind  ValueKind
+	Text  string  // Unquoted text; empty for lists
+	Raw   string  // The value as written; empty for lists
+	Items []Value // List items
+	pos   int
+}
+
+func (n *And) Pos() int        { return n.pos }
+func (n *Or) Pos() int         { return n.pos }
+func (n *Not) Pos() int        { return n.pos }
+func (n *Comparison) Pos() int { return n.pos }
+
+func (n *And) String() string {
+	parts := make([]string, len(n.Terms))
+	for i, t := range n.Terms {
+		// OR binds more loosely than AND, so OR terms need parentheses
+		if _, ok := t.(*Or); ok {
+			parts[i] = "(" + t.String() + ")"
+		} else {
+			parts[i] = t.String()
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+func (n *Or) String() string {
+	parts := make([]string, len(n.Terms))
+	for i, t := range n.Terms {
+		// AND terms are parenthesized too, so the grouping does not depend on
+		// precedence when the expression is read back or sent to the API
+		if _, ok := t.(*And); ok {
+			parts[i] = "(" + t.String() + ")"
+		} else {
+			parts[i] = t.String()
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (n *Not) String() string {
+	if _, ok := n.X.(*Comparison); ok {
+		return "!" + n.X.String()
+	}
+	return "!(" + n.X.String() + ")"
+}
+
+func (n *Comparison) String() string {
+	return n.Field + ":" + n.Operator + n.Value.String()
+}
+
+func (v Value) String() string {
+	if v.Kind != ListValue {
+		return v.Raw
+	}
+	parts := make([]string, len(v.Items))
+	for i, item := range v.Items {
+		parts[i] = item.String()
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// Quote returns s as a single-quoted FQL string
+func Quote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// dateMathPattern matches relative dates: now, optionally followed by offsets
+// such as -7d or +1h, and an optional rounding unit such as /d
+var dateMathPattern = regexp.MustCompile(`^now([+-][0-9]+[smhdwMy])*(/[smhdwMy])?$`)
+
+// isDateMath reports whether a value is meant as date math: now on its own or
+// followed by '+', '-' or '/'. Other values starting with now, such as a
+// hostname like nowak-pc, are plain values.
+func isDateMath(s string) bool {
+	if !strings.HasPrefix(s, "now") {
+		return false
+	}
+	rest := s[len("now"):]
+	return rest == "" || rest[0] == '+' || rest[0] == '-' || rest[0] == '/'
+}
+
+// parser is a recursive descent parser over a token list
+type parser struct {
+	input  string
+	tokens []token
+	pos    int
+}
+
+// Parse parses a filter expression
+func Parse(input string) (Node, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, &SyntaxError{Input: input, Offset: 0, Msg: "empty filter"}
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{input: input, tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		if tok.kind == tokenRParen {
+			return nil, p.errorAt(tok, "unbalanced ')'")
+		}
+		return nil, p.errorAt(tok, fmt.Sprintf("unexpected %s, expected '+' or ','", describe(tok)))
+	}
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorAt(tok token, msg string) error {
+	return &SyntaxError{Input: p.input, Offset: tok.start, Msg: msg}
+}
+
+// describe names a token for error messages
+func describe(tok token) string {
+	if tok.kind == tokenWord || tok.kind == tokenString || tok.kind == tokenOperator {
+		return fmt.Sprintf("'%s'", tok.raw)
+	}
+	return tok.kind.String()
+}
+
+// parseOr parses terms separated by ','
+func (p *parser) parseOr() (Node, error) {
+	start := p.peek().start
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	terms := []Node{first}
+	for p.peek().kind == tokenOr {
+		p.next()
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return &Or{Terms: terms, pos: start}, nil
+}
+
+// parseAnd parses terms separated by '+'
+func (p *parser) parseAnd() (Node, error) {
+	start := p.peek().start
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	terms := []Node{first}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		term, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return &And{Terms: terms, pos: start}, nil
+}
+
+// parseUnary parses an optionally negated term
+func (p *parser) parseUnary() (Node, error) {
+	if tok := p.peek(); tok.kind == tokenNot {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x, pos: tok.start}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a parenthesized expression or a comparison
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorAt(closing, fmt.Sprintf("expected ')' to close '(' at column %d", (&SyntaxError{Input: p.input, Offset: tok.start}).Column()))
+		}
+		return node, nil
+	case tokenWord:
+		return p.parseComparison(tok)
+	case tokenEOF:
+		return nil, p.errorAt(tok, "unexpected end of filter, expected a field name")
+	}
+	return nil, p.errorAt(tok, fmt.Sprintf("unexpected %s, expected a field name", describe(tok)))
+}
+
+// parseComparison parses the rest of field:[operator]value
+func (p *parser) parseComparison(field token) (Node, error) {
+	if colon := p.next(); colon.kind != tokenColon {
+		return nil, p.errorAt(colon, fmt.Sprintf("expected ':' after field name '%s'", field.text))
+	}
+
+	comparison := &Comparison{Field: field.text, pos: field.start}
+	if tok := p.peek(); tok.kind == tokenOperator || tok.kind == tokenNot {
+		p.next()
+		comparison.Operator = tok.text
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	comparison.Value = value
+	return comparison, nil
+}
+
+// parseValue parses a single value or a list of values
+func (p *parser) parseValue() (Value, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		value := Value{Kind: StringValue, Text: tok.text, Raw: tok.raw, pos: tok.start}
+		if isDateMath(tok.text) {
+			if !dateMathPattern.MatchString(tok.text) {
+				return Value{}, p.errorAt(tok, fmt.Sprintf("invalid date math '%s' (expected e.g. 'now-7d' or 'now-1h/d')", tok.text))
+			}
+			value.Kind = DateMathValue
+		}
+		return value, nil
+	case tokenWord:
+		return p.classifyWord(tok)
+	case tokenLBracket:
+		list := Value{Kind: ListValue, pos: tok.start}
+		for {
+			if len(list.Items) > 0 || p.peek().kind != tokenRBracket {
+				item, err := p.parseValue()
+				if err != nil {
+					return Value{}, err
+				}
+				if item.Kind == ListValue {
+					return Value{}, p.errorAt(tokenAt(item.pos), "lists cannot be nested")
+				}
+				list.Items = append(list.Items, item)
+			}
+
+			switch next := p.next(); next.kind {
+			case tokenOr:
+				continue
+			case tokenRBracket:
+				if len(list.Items) == 0 {
+					return Value{}, p.errorAt(next, "empty list")
+				}
+				return list, nil
+			default:
+				return Value{}, p.errorAt(next, fmt.Sprintf("unexpected %s in list, expected ',' or ']'", describe(next)))
+			}
+		}
+	case tokenEOF:
+		return Value{}, p.errorAt(tok, "unexpected end of filter, expected a value")
+	}
+	return Value{}, p.errorAt(tok, fmt.Sprintf("unexpected %s, expected a value", describe(tok)))
+}
+
+// classifyWord works out what kind of value an unquoted word is
+func (p *parser) classifyWord(tok token) (Value, error) {
+	value := Value{Kind: WordValue, Text: tok.text, Raw: tok.raw, pos: tok.start}
+	switch {
+	case tok.text == "true" || tok.text == "false":
+		value.Kind = BoolValue
+	case tok.text == "null":
+		value.Kind = NullValue
+	case isDateMath(tok.text):
+		if !dateMathPattern.MatchString(tok.text) {
+			return Value{}, p.errorAt(tok, fmt.Sprintf("invalid date math '%s' (expected e.g. now-7d or now-1h/d)", tok.text))
+		}
+		value.Kind = DateMathValue
+	default:
+		if _, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			value.Kind = NumberValue
+		}
+	}
+	return value, nil
+}
+
+// tokenAt returns a placeholder token at an offset, for error reporting
+func tokenAt(offset int) token {
+	return token{start: offset}
+}
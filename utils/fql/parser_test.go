@@ -0,0 +1,159 @@
+package fql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "platform_name:'Windows'", want: "platform_name:'Windows'"},
+		{input: "a:1+b:2,c:3", want: "(a:1+b:2),c:3"},
+		{input: "a:1,b:2+c:3", want: "a:1,(b:2+c:3)"},
+		{input: "(a:1+b:2),c:3", want: "(a:1+b:2),c:3"},
+		{input: "!(a:1+b:2,c:3)", want: "!((a:1+b:2),c:3)"},
+		{input: "(a:1,b:2)+c:3", want: "(a:1,b:2)+c:3"},
+		{input: " a:1 + ( b:2 , c:3 ) ", want: "a:1+(b:2,c:3)"},
+		{input: "!status:'contained'", want: "!status:'contained'"},
+		{input: "!(a:1+b:2)", want: "!(a:1+b:2)"},
+		{input: "last_seen:>='now-7d'", want: "last_seen:>='now-7d'"},
+		{input: "platform_name:['Windows','Mac']", want: "platform_name:['Windows','Mac']"},
+		{input: "hostname:*'web*'", want: "hostname:*'web*'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			if got := node.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		msg   string
+	}{
+		{input: "", msg: "empty filter"},
+		{input: "hostname", msg: "expected ':' after field name 'hostname'"},
+		{input: "hostname:", msg: "unexpected end of filter, expected a value"},
+		{input: "a:1+", msg: "unexpected end of filter, expected a field name"},
+		{input: "(a:1", msg: "expected ')' to close '(' at column 1"},
+		{input: "a:1)", msg: "unbalanced ')'"},
+		{input: "a:1 b:2", msg: "unexpected 'b', expected '+' or ','"},
+		{input: "a:[]", msg: "empty list"},
+		{input: "a:[1,[2]]", msg: "lists cannot be nested"},
+		{input: "a:[1 2]", msg: "unexpected '2' in list, expected ',' or ']'"},
+		{input: "last_seen:>now-7x", msg: "invalid date math 'now-7x'"},
+		{input: "last_seen:>'now+'", msg: "invalid date math 'now+'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var serr *SyntaxError
+			if !errors.As(err, &serr) {
+				t.Fatalf("Parse(%q) error = %v, want a SyntaxError", tt.input, err)
+			}
+			if !strings.HasPrefix(serr.Msg, tt.msg) {
+				t.Errorf("Parse(%q) error = %q, want %q", tt.input, serr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseValueKinds(t *testing.T) {
+	tests := []struct {
+		input string
+		kind  ValueKind
+	}{
+		{input: "a:'text'", kind: StringValue},
+		{input: "a:42", kind: NumberValue},
+		{input: "a:-1.5", kind: NumberValue},
+		{input: "a:true", kind: BoolValue},
+		{input: "a:null", kind: NullValue},
+		{input: "a:web-01", kind: WordValue},
+		{input: "a:now", kind: DateMathValue},
+		{input: "a:now-7d", kind: DateMathValue},
+		{input: "a:'now-1h/d'", kind: DateMathValue},
+		{input: "a:[1,2]", kind: ListValue},
+		{input: "hostname:'nowak-pc'", kind: StringValue},
+		{input: "hostname:'nowhere-01'", kind: StringValue},
+		{input: "hostname:nowhere-01", kind: WordValue},
+		{input: "hostname:'now'", kind: DateMathValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			c, ok := node.(*Comparison)
+			if !ok {
+				t.Fatalf("Parse(%q) = %T, want *Comparison", tt.input, node)
+			}
+			if c.Value.Kind != tt.kind {
+				t.Errorf("Parse(%q) value kind = %d, want %d", tt.input, c.Value.Kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestIsDateMath(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "now", want: true},
+		{value: "now-7d", want: true},
+		{value: "now+1h", want: true},
+		{value: "now/d", want: true},
+		{value: "now-", want: true},
+		{value: "nowak-pc", want: false},
+		{value: "nowhere-01", want: false},
+		{value: "now_server", want: false},
+		{value: "snow", want: false},
+		{value: "", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isDateMath(tt.value); got != tt.want {
+			t.Errorf("isDateMath(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "web", want: "'web'"},
+		{in: "it's", want: `'it\'s'`},
+		{in: `a\b`, want: `'a\\b'`},
+	}
+
+	for _, tt := range tests {
+		got := Quote(tt.in)
+		if got != tt.want {
+			t.Errorf("Quote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		// A quoted value parses back to the original text
+		node, err := Parse("a:" + got)
+		if err != nil {
+			t.Fatalf("Parse of Quote(%q) returned error: %v", tt.in, err)
+		}
+		if text := node.(*Comparison).Value.Text; text != tt.in {
+			t.Errorf("Quote(%q) parsed back to %q", tt.in, text)
+		}
+	}
+}
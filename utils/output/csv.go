@@ -0,0 +1,63 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+func init() {
+	Register("csv", newCSVPrinter)
+}
+
+// csvPrinter renders records as CSV rows, writing each row as it is added
+type csvPrinter struct {
+	w             *csv.Writer
+	opts          Options
+	headerWritten bool
+}
+
+func newCSVPrinter(w io.Writer, opts Options) Printer {
+	return &csvPrinter{w: csv.NewWriter(w), opts: opts}
+}
+
+func (p *csvPrinter) Add(record interface{}) error {
+	if err := p.writeHeader(); err != nil {
+		return err
+	}
+
+	cells, err := row(record, p.opts.Columns)
+	if err != nil {
+		return err
+	}
+	if err := p.w.Write(cells); err != nil {
+		return err
+	}
+	p.w.Flush()
+	return p.w.Error()
+}
+
+func (p *csvPrinter) Flush() error {
+	if err := p.writeHeader(); err != nil {
+		return err
+	}
+	p.w.Flush()
+	return p.w.Error()
+}
+
+// writeHeader writes the header row once, unless headers are disabled
+func (p *csvPrinter) writeHeader() error {
+	if p.headerWritten || p.opts.NoHeaders {
+		return nil
+	}
+	p.headerWritten = true
+
+	names := make([]string, len(p.opts.Columns))
+	for i, c := range p.opts.Columns {
+		// Use field names so the header matches what --columns accepts
+		names[i] = c.Field
+		if names[i] == "" {
+			names[i] = c.Header
+		}
+	}
+	return p.w.Write(names)
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func init() {
+	Register("json", newJSONPrinter)
+	Register("ndjson", newNDJSONPrinter)
+}
+
+// jsonPrinter renders records as a pretty-printed JSON array, writing each
+// element as it is added
+type jsonPrinter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONPrinter(w io.Writer, _ Options) Printer {
+	return &jsonPrinter{w: w}
+}
+
+func (p *jsonPrinter) Add(record interface{}) error {
+	data, err := json.MarshalIndent(record, "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding record: %v", err)
+	}
+
+	sep := ",\n  "
+	if p.count == 0 {
+		sep = "[\n  "
+	}
+	p.count++
+
+	_, err = fmt.Fprintf(p.w, "%s%s", sep, data)
+	return err
+}
+
+func (p *jsonPrinter) Flush() error {
+	if p.count == 0 {
+		_, err := fmt.Fprintln(p.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(p.w, "\n]")
+	return err
+}
+
+// ndjsonPrinter renders each record as a single line of JSON, suitable for streaming
+type ndjsonPrinter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONPrinter(w io.Writer, _ Options) Printer {
+	return &ndjsonPrinter{enc: json.NewEncoder(w)}
+}
+
+func (p *ndjsonPrinter) Add(record interface{}) error {
+	if err := p.enc.Encode(record); err != nil {
+		return fmt.Errorf("error encoding record: %v", err)
+	}
+	return nil
+}
+
+func (p *ndjsonPrinter) Flush() error {
+	return nil
+}
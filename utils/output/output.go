@@ -0,0 +1,192 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultFormat is the output format used when --output is not given
+const DefaultFormat = "table"
+
+// Column describes a field rendered by the table and CSV formatters
+type Column struct {
+	Header string
+	Field  string // Dot-separated path into the record's JSON form; empty means the record itself
+}
+
+// Options configures a Printer
+type Options struct {
+	Columns   []Column
+	NoHeaders bool
+	MaxWidth  int // Maximum width of a table cell (0 means unlimited)
+}
+
+// Printer writes records in a particular output format
+type Printer interface {
+	// Add adds a record. Streaming formats write it immediately.
+	Add(record interface{}) error
+	// Flush writes any buffered records. It is called once, after the last Add.
+	Flush() error
+}
+
+// Factory creates a Printer that writes to w
+type Factory func(w io.Writer, opts Options) Printer
+
+var registry = map[string]Factory{}
+
+// Register makes a formatter available under the given name
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// Names returns the names of all registered formatters
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a Printer for the named format
+func New(name string, w io.Writer, opts Options) (Printer, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format '%s' (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(w, opts), nil
+}
+
+// AddFlags adds the output flags to cmd as persistent flags
+func AddFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringP("output", "o", DefaultFormat, fmt.Sprintf("Output format (%s)", strings.Join(Names(), ", ")))
+	cmd.PersistentFlags().StringSlice("columns", nil, "Columns to show in table and CSV output (e.g., hostname,os_version)")
+	cmd.PersistentFlags().Bool("no-headers", false, "Omit the header row in table and CSV output")
+	cmd.PersistentFlags().Int("max-width", 50, "Maximum width of a table cell (0 means unlimited)")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(Names(), cobra.ShellCompDirectiveNoFileComp))
+}
+
+// NewFromFlags creates a Printer writing to the command's output, configured from
+// the flags added by AddFlags. defaults are the columns shown when --columns is not given.
+func NewFromFlags(cmd *cobra.Command, defaults []Column) (Printer, error) {
+	format, _ := cmd.Flags().GetString("output")
+	names, _ := cmd.Flags().GetStringSlice("columns")
+	noHeaders, _ := cmd.Flags().GetBool("no-headers")
+	maxWidth, _ := cmd.Flags().GetInt("max-width")
+
+	if format == "" {
+		format = DefaultFormat
+	}
+
+	return New(format, cmd.OutOrStdout(), Options{
+		Columns:   SelectColumns(defaults, names),
+		NoHeaders: noHeaders,
+		MaxWidth:  maxWidth,
+	})
+}
+
+// SelectColumns returns the columns matching names, falling back to defaults when
+// names is empty. Names that are not default columns are treated as field paths.
+func SelectColumns(defaults []Column, names []string) []Column {
+	if len(names) == 0 {
+		return defaults
+	}
+
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		column := Column{Header: strings.ToUpper(strings.ReplaceAll(name, "_", " ")), Field: name}
+		for _, d := range defaults {
+			if strings.EqualFold(d.Field, name) || strings.EqualFold(d.Header, name) {
+				column = d
+				break
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// normalize converts a record to its generic JSON form so fields can be looked up by name
+func normalize(record interface{}) (interface{}, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding record: %v", err)
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("error encoding record: %v", err)
+	}
+	return generic, nil
+}
+
+// lookup returns the value at a dot-separated path in a normalized record
+func lookup(record interface{}, path string) interface{} {
+	if path == "" {
+		return record
+	}
+
+	value := record
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// cell renders a normalized value as a single line of text
+func cell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = cell(item)
+		}
+		return strings.Join(parts, ",")
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// row renders the given columns of a record as text cells
+func row(record interface{}, columns []Column) ([]string, error) {
+	generic, err := normalize(record)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make([]string, len(columns))
+	for i, c := range columns {
+		cells[i] = cell(lookup(generic, c.Field))
+	}
+	return cells, nil
+}
+
+// headers returns the header row for the given columns
+func headers(columns []Column) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Header
+	}
+	return names
+}
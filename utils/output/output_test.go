@@ -0,0 +1,248 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+// testHost is the record type printed by the tests
+type testHost struct {
+	Hostname string   `json:"hostname"`
+	DeviceID string   `json:"device_id"`
+	Online   bool     `json:"online"`
+	Tags     []string `json:"tags"`
+	Policy   struct {
+		Name string `json:"name"`
+	} `json:"policy"`
+	Cores int `json:"cores"`
+}
+
+// testHosts returns the records printed by the tests
+func testHosts() []testHost {
+	web := testHost{Hostname: "web-1", DeviceID: "abc123", Online: true, Tags: []string{"FalconGroupingTags/web", "FalconGroupingTags/prod"}, Cores: 8}
+	web.Policy.Name = "Default"
+	db := testHost{Hostname: "db-server-with-a-long-name", DeviceID: "def456", Cores: 16}
+	db.Policy.Name = "Servers, strict"
+	return []testHost{web, db}
+}
+
+// testColumns are the default columns of the tests
+var testColumns = []Column{
+	{Header: "HOSTNAME", Field: "hostname"},
+	{Header: "DEVICE ID", Field: "device_id"},
+	{Header: "ONLINE", Field: "online"},
+	{Header: "TAGS", Field: "tags"},
+}
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		opts   Options
+		want   string
+	}{
+		{
+			name:   "table",
+			format: "table",
+			opts:   Options{Columns: testColumns},
+			want: `HOSTNAME                    DEVICE ID  ONLINE  TAGS
+web-1                       abc123     true    FalconGroupingTags/web,FalconGroupingTags/prod
+db-server-with-a-long-name  def456     false   
+`,
+		},
+		{
+			name:   "table without headers and narrow cells",
+			format: "table",
+			opts:   Options{Columns: testColumns, NoHeaders: true, MaxWidth: 10},
+			want: `web-1       abc123  true   FalconGro…
+db-server…  def456  false  
+`,
+		},
+		{
+			name:   "csv",
+			format: "csv",
+			opts:   Options{Columns: testColumns},
+			want: `hostname,device_id,online,tags
+web-1,abc123,true,"FalconGroupingTags/web,FalconGroupingTags/prod"
+db-server-with-a-long-name,def456,false,
+`,
+		},
+		{
+			name:   "json",
+			format: "json",
+			opts:   Options{Columns: testColumns},
+			want: `[
+  {
+    "hostname": "web-1",
+    "device_id": "abc123",
+    "online": true,
+    "tags": [
+      "FalconGroupingTags/web",
+      "FalconGroupingTags/prod"
+    ],
+    "policy": {
+      "name": "Default"
+    },
+    "cores": 8
+  },
+  {
+    "hostname": "db-server-with-a-long-name",
+    "device_id": "def456",
+    "online": false,
+    "tags": null,
+    "policy": {
+      "name": "Servers, strict"
+    },
+    "cores": 16
+  }
+]
+`,
+		},
+		{
+			name:   "ndjson",
+			format: "ndjson",
+			opts:   Options{Columns: testColumns},
+			want: `{"hostname":"web-1","device_id":"abc123","online":true,"tags":["FalconGroupingTags/web","FalconGroupingTags/prod"],"policy":{"name":"Default"},"cores":8}
+{"hostname":"db-server-with-a-long-name","device_id":"def456","online":false,"tags":null,"policy":{"name":"Servers, strict"},"cores":16}
+`,
+		},
+		{
+			name:   "yaml",
+			format: "yaml",
+			opts:   Options{Columns: testColumns},
+			want: `- cores: 8
+  device_id: abc123
+  hostname: web-1
+  online: true
+  policy:
+    name: Default
+  tags:
+    - FalconGroupingTags/web
+    - FalconGroupingTags/prod
+- cores: 16
+  device_id: def456
+  hostname: db-server-with-a-long-name
+  online: false
+  policy:
+    name: Servers, strict
+  tags: null
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printer, err := New(tt.format, &buf, tt.opts)
+			if err != nil {
+				t.Fatalf("New returned error: %v", err)
+			}
+			for _, host := range testHosts() {
+				if err := printer.Add(host); err != nil {
+					t.Fatalf("Add returned error: %v", err)
+				}
+			}
+			if err := printer.Flush(); err != nil {
+				t.Fatalf("Flush returned error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestEmptyOutput(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "table", want: "HOSTNAME  DEVICE ID  ONLINE  TAGS\n"},
+		{format: "csv", want: "hostname,device_id,online,tags\n"},
+		{format: "json", want: "[]\n"},
+		{format: "ndjson", want: ""},
+		{format: "yaml", want: "[]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			printer, err := New(tt.format, &buf, Options{Columns: testColumns})
+			if err != nil {
+				t.Fatalf("New returned error: %v", err)
+			}
+			if err := printer.Flush(); err != nil {
+				t.Fatalf("Flush returned error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		format  string
+		want    string
+	}{
+		{
+			name:   "default columns",
+			format: "csv",
+			want:   "hostname,device_id,online,tags\nweb-1,abc123,true,\"FalconGroupingTags/web,FalconGroupingTags/prod\"\ndb-server-with-a-long-name,def456,false,\n",
+		},
+		{
+			name:    "subset in given order",
+			columns: []string{"device_id", "hostname"},
+			format:  "csv",
+			want:    "device_id,hostname\nabc123,web-1\ndef456,db-server-with-a-long-name\n",
+		},
+		{
+			name:    "by header name, case-insensitive",
+			columns: []string{"Device ID", "HOSTNAME"},
+			format:  "table",
+			want:    "DEVICE ID  HOSTNAME\nabc123     web-1\ndef456     db-server-with-a-long-name\n",
+		},
+		{
+			name:    "field paths outside the defaults",
+			columns: []string{"hostname", "policy.name", "cores", "missing"},
+			format:  "table",
+			want:    "HOSTNAME                    POLICY.NAME      CORES  MISSING\nweb-1                       Default          8      \ndb-server-with-a-long-name  Servers, strict  16     \n",
+		},
+		{
+			name:    "field paths in csv",
+			columns: []string{" hostname ", "policy.name"},
+			format:  "csv",
+			want:    "hostname,policy.name\nweb-1,Default\ndb-server-with-a-long-name,\"Servers, strict\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printer, err := New(tt.format, &buf, Options{Columns: SelectColumns(testColumns, tt.columns)})
+			if err != nil {
+				t.Fatalf("New returned error: %v", err)
+			}
+			for _, host := range testHosts() {
+				if err := printer.Add(host); err != nil {
+					t.Fatalf("Add returned error: %v", err)
+				}
+			}
+			if err := printer.Flush(); err != nil {
+				t.Fatalf("Flush returned error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output =\n%q\nwant\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := New("xml", &bytes.Buffer{}, Options{}); err == nil {
+		t.Error("New accepted an unknown format")
+	}
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+func init() {
+	Register("table", newTablePrinter)
+}
+
+// tablePrinter renders records as an aligned, human-readable table
+type tablePrinter struct {
+	w    io.Writer
+	opts Options
+	rows [][]string
+}
+
+func newTablePrinter(w io.Writer, opts Options) Printer {
+	return &tablePrinter{w: w, opts: opts}
+}
+
+// Add buffers a record; column widths are only known once every row is in
+func (p *tablePrinter) Add(record interface{}) error {
+	cells, err := row(record, p.opts.Columns)
+	if err != nil {
+		return err
+	}
+	p.rows = append(p.rows, cells)
+	return nil
+}
+
+// Flush writes the header and all buffered rows
+func (p *tablePrinter) Flush() error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	if !p.opts.NoHeaders {
+		p.writeRow(tw, headers(p.opts.Columns))
+	}
+	for _, cells := range p.rows {
+		p.writeRow(tw, cells)
+	}
+	p.rows = nil
+	return tw.Flush()
+}
+
+func (p *tablePrinter) writeRow(w io.Writer, cells []string) {
+	for i, c := range cells {
+		cells[i] = truncate(c, p.opts.MaxWidth)
+	}
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+}
+
+// truncate shortens s to at most width runes, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	// Tabs and newlines would break the table layout
+	s = strings.NewReplacer("\t", " ", "\n", " ", "\r", "").Replace(s)
+
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,41 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	Register("yaml", newYAMLPrinter)
+}
+
+// yamlPrinter renders records as a single YAML list
+type yamlPrinter struct {
+	w       io.Writer
+	records []interface{}
+}
+
+func newYAMLPrinter(w io.Writer, _ Options) Printer {
+	return &yamlPrinter{w: w, records: []interface{}{}}
+}
+
+// Add buffers a record in its JSON form so YAML keys match the API field names
+func (p *yamlPrinter) Add(record interface{}) error {
+	generic, err := normalize(record)
+	if err != nil {
+		return err
+	}
+	p.records = append(p.records, generic)
+	return nil
+}
+
+func (p *yamlPrinter) Flush() error {
+	data, err := yaml.Marshal(p.records)
+	if err != nil {
+		return fmt.Errorf("error encoding YAML: %v", err)
+	}
+	_, err = p.w.Write(data)
+	return err
+}
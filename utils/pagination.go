@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DefaultPageSize is the page size used when no limit is given
+const DefaultPageSize = 100
+
+// Pagination represents the pagination block of a Falcon query response
+type Pagination struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Total  int `json:"total"`
+}
+
+// QueryMeta represents the meta block of a Falcon query response
+type QueryMeta struct {
+	QueryTime  float64    `json:"query_time"`
+	PoweredBy  string     `json:"powered_by"`
+	TraceID    string     `json:"trace_id"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// QueryResponse represents the response from a Falcon query endpoint that returns IDs
+type QueryResponse struct {
+	Resources []string `json:"resources"`
+	Errors    []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Meta QueryMeta `json:"meta"`
+}
+
+// PageOptions controls how QueryIDs walks an offset/limit paginated endpoint
+type PageOptions struct {
+	Limit      int  // Page size sent to the API (0 uses DefaultPageSize)
+	Offset     int  // Offset of the first result to fetch
+	All        bool // Keep fetching pages until the result set is exhausted
+	MaxResults int  // Stop after this many results (0 means no cap)
+}
+
+// PageFunc is called with each page of IDs as it arrives
+type PageFunc func(ids []string, meta QueryMeta) error
+
+// MaxQueryOffset is the furthest offset+limit that offset/limit paginated query
+// endpoints accept; results past it can only be reached through a scroll endpoint
+const MaxQueryOffset = 10000
+
+// scrollEndpoints maps offset/limit query endpoints to a scroll endpoint that
+// returns the same IDs without the offset limit
+var scrollEndpoints = map[string]string{
+	"/devices/queries/devices/v1": "/devices/queries/devices-scroll/v1",
+}
+
+// QueryIDs walks an offset/limit paginated query endpoint, calling fn with each page
+// of IDs as it is received. Only one page is fetched unless opts.All is set or
+// opts.MaxResults asks for more results than fit in a single page. It returns the
+// number of IDs passed to fn and the meta block of the last page.
+//
+// Walks from the first result of an endpoint with a scroll equivalent use the
+// scroll endpoint. Other walks that would go past MaxQueryOffset fail before any
+// IDs are passed to fn, rather than partway through the results.
+func (fc *FalconClient) QueryIDs(endpoint string, params map[string]string, opts PageOptions, fn PageFunc) (int, QueryMeta, error) {
+	pageSize := opts.Limit
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	walk := opts.All || opts.MaxResults > pageSize
+
+	if scroll, ok := scrollEndpoints[endpoint]; ok && walk && opts.Offset == 0 {
+		return fc.scrollIDs(scroll, params, pageSize, opts.MaxResults, fn)
+	}
+	if end := opts.Offset + pageLimit(pageSize, opts.MaxResults, 0); end > MaxQueryOffset {
+		return 0, QueryMeta{}, offsetLimitError(endpoint, end)
+	}
+
+	offset := opts.Offset
+	fetched := 0
+	var meta QueryMeta
+
+	for {
+		limit := pageLimit(pageSize, opts.MaxResults, fetched)
+
+		// Copy the caller's params so each page gets its own offset and limit
+		query := make(map[string]string, len(params)+2)
+		for key, value := range params {
+			query[key] = value
+		}
+		query["limit"] = strconv.Itoa(limit)
+		query["offset"] = strconv.Itoa(offset)
+
+		resp, err := fc.Get(endpoint, query)
+		if err != nil {
+			return fetched, meta, err
+		}
+
+		var page QueryResponse
+		if err := fc.ParseResponse(resp, &page); err != nil {
+			return fetched, meta, err
+		}
+		meta = page.Meta
+
+		// The total is known once the first page arrives, so check the whole walk fits
+		if walk && fetched == 0 {
+			if end := walkEnd(opts, page.Meta.Pagination.Total); end > MaxQueryOffset {
+				return 0, meta, offsetLimitError(endpoint, end)
+			}
+		}
+
+		if len(page.Resources) > 0 {
+			if err := fn(page.Resources, page.Meta); err != nil {
+				return fetched, meta, err
+			}
+		}
+		fetched += len(page.Resources)
+		offset += len(page.Resources)
+
+		if !walk || len(page.Resources) == 0 || offset >= page.Meta.Pagination.Total {
+			break
+		}
+		if opts.MaxResults > 0 && fetched >= opts.MaxResults {
+			break
+		}
+	}
+
+	return fetched, meta, nil
+}
+
+// scrollResponse represents the response from a scroll query endpoint, whose
+// pagination offset is a token for the next page rather than a number
+type scrollResponse struct {
+	Resources []string `json:"resources"`
+	Meta      struct {
+		QueryTime  float64 `json:"query_time"`
+		PoweredBy  string  `json:"powered_by"`
+		TraceID    string  `json:"trace_id"`
+		Pagination struct {
+			Offset string `json:"offset"`
+			Total  int    `json:"total"`
+		} `json:"pagination"`
+	} `json:"meta"`
+}
+
+// scrollIDs walks a scroll query endpoint, sending the token from each page as
+// the offset of the next. The meta blocks passed to fn count the offset in results
+// like QueryIDs does.
+func (fc *FalconClient) scrollIDs(endpoint string, params map[string]string, pageSize, maxResults int, fn PageFunc) (int, QueryMeta, error) {
+	fetched := 0
+	after := ""
+	var meta QueryMeta
+
+	for {
+		limit := pageLimit(pageSize, maxResults, fetched)
+
+		query := make(map[string]string, len(params)+2)
+		for key, value := range params {
+			query[key] = value
+		}
+		query["limit"] = strconv.Itoa(limit)
+		if after != "" {
+			query["offset"] = after
+		}
+
+		resp, err := fc.Get(endpoint, query)
+		if err != nil {
+			return fetched, meta, err
+		}
+
+		var page scrollResponse
+		if err := fc.ParseResponse(resp, &page); err != nil {
+			return fetched, meta, err
+		}
+		meta = QueryMeta{
+			QueryTime:  page.Meta.QueryTime,
+			PoweredBy:  page.Meta.PoweredBy,
+			TraceID:    page.Meta.TraceID,
+			Pagination: Pagination{Offset: fetched, Limit: limit, Total: page.Meta.Pagination.Total},
+		}
+
+		if len(page.Resources) > 0 {
+			if err := fn(page.Resources, meta); err != nil {
+				return fetched, meta, err
+			}
+		}
+		fetched += len(page.Resources)
+		after = page.Meta.Pagination.Offset
+
+		if len(page.Resources) == 0 || after == "" || fetched >= page.Meta.Pagination.Total {
+			break
+		}
+		if maxResults > 0 && fetched >= maxResults {
+			break
+		}
+	}
+
+	return fetched, meta, nil
+}
+
+// pageLimit returns the limit of the next page, so that no more than maxResults
+// results are fetched in total (0 means no cap)
+func pageLimit(pageSize, maxResults, fetched int) int {
+	if maxResults > 0 && maxResults-fetched < pageSize {
+		return maxResults - fetched
+	}
+	return pageSize
+}
+
+// walkEnd returns the offset just past the last result a walk over total
+// results would fetch
+func walkEnd(opts PageOptions, total int) int {
+	if opts.MaxResults > 0 && opts.Offset+opts.MaxResults < total {
+		return opts.Offset + opts.MaxResults
+	}
+	return total
+}
+
+// offsetLimitError explains that a query would page past MaxQueryOffset
+func offsetLimitError(endpoint string, end int) error {
+	return fmt.Errorf("this query would page through %d results, but %s only returns the first %d; narrow the filter, or lower --offset or --max-results", end, endpoint, MaxQueryOffset)
+}
+
+// Count returns the number of results matching params by requesting a single
+// result and reading the total from the pagination meta block
+func (fc *FalconClient) Count(endpoint string, params map[string]string) (int, QueryMeta, error) {
+	query := make(map[string]string, len(params)+1)
+	for key, value := range params {
+		query[key] = value
+	}
+	query["limit"] = "1"
+
+	resp, err := fc.Get(endpoint, query)
+	if err != nil {
+		return 0, QueryMeta{}, err
+	}
+
+	var page QueryResponse
+	if err := fc.ParseResponse(resp, &page); err != nil {
+		return 0, QueryMeta{}, err
+	}
+	return page.Meta.Pagination.Total, page.Meta, nil
+}
+
+// Validate checks page options gathered from command flags
+func (opts PageOptions) Validate() error {
+	if opts.Limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	if opts.Offset < 0 {
+		return fmt.Errorf("--offset must not be negative")
+	}
+	if opts.MaxResults < 0 {
+		return fmt.Errorf("--max-results must not be negative")
+	}
+	return nil
+}
+
+// ChunkIDs splits ids into batches of at most size IDs
+func ChunkIDs(ids []string, size int) [][]string {
+	var chunks [][]string
+	for size < len(ids) {
+		chunks = append(chunks, ids[:size:size])
+		ids = ids[size:]
+	}
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+	return chunks
+}
@@ -0,0 +1,336 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client for srv with a valid token and no retries
+func newTestClient(srv *httptest.Server) *FalconClient {
+	return &FalconClient{
+		BaseURL: srv.URL,
+		Tokens:  &TokenManager{token: "test-token", expiresAt: time.Now().Add(time.Hour)},
+		Client:  srv.Client(),
+	}
+}
+
+// makeIDs returns n IDs named id-0, id-1, ...
+func makeIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id-%d", i)
+	}
+	return ids
+}
+
+// offsetServer serves total IDs from an offset/limit query endpoint and records
+// the offset and limit of each request
+type offsetServer struct {
+	total    int
+	requests [][2]int
+}
+
+func (s *offsetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	s.requests = append(s.requests, [2]int{offset, limit})
+
+	ids := makeIDs(s.total)
+	end := min(offset+limit, s.total)
+	resources := []string{}
+	if offset < end {
+		resources = ids[offset:end]
+	}
+	json.NewEncoder(w).Encode(QueryResponse{
+		Resources: resources,
+		Meta:      QueryMeta{Pagination: Pagination{Offset: offset, Limit: limit, Total: s.total}},
+	})
+}
+
+func TestQueryIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		opts     PageOptions
+		requests [][2]int // Offset and limit of each request
+		fetched  int
+	}{
+		{
+			name:     "single page with default size",
+			total:    250,
+			opts:     PageOptions{},
+			requests: [][2]int{{0, 100}},
+			fetched:  100,
+		},
+		{
+			name:     "single page at an offset",
+			total:    250,
+			opts:     PageOptions{Limit: 50, Offset: 220},
+			requests: [][2]int{{220, 50}},
+			fetched:  30,
+		},
+		{
+			name:     "all pages",
+			total:    250,
+			opts:     PageOptions{All: true},
+			requests: [][2]int{{0, 100}, {100, 100}, {200, 100}},
+			fetched:  250,
+		},
+		{
+			name:     "all pages from an offset",
+			total:    250,
+			opts:     PageOptions{Limit: 100, Offset: 120, All: true},
+			requests: [][2]int{{120, 100}, {220, 100}},
+			fetched:  130,
+		},
+		{
+			name:     "max results shrinks the last page",
+			total:    250,
+			opts:     PageOptions{Limit: 100, MaxResults: 230},
+			requests: [][2]int{{0, 100}, {100, 100}, {200, 30}},
+			fetched:  230,
+		},
+		{
+			name:     "max results smaller than a page",
+			total:    250,
+			opts:     PageOptions{MaxResults: 10, All: true},
+			requests: [][2]int{{0, 10}},
+			fetched:  10,
+		},
+		{
+			name:     "max results past the total",
+			total:    150,
+			opts:     PageOptions{Limit: 100, MaxResults: 500},
+			requests: [][2]int{{0, 100}, {100, 100}},
+			fetched:  150,
+		},
+		{
+			name:     "no results",
+			total:    0,
+			opts:     PageOptions{All: true},
+			requests: [][2]int{{0, 100}},
+			fetched:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &offsetServer{total: tt.total}
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+
+			var got []string
+			fetched, meta, err := newTestClient(srv).QueryIDs("/test/queries/v1", nil, tt.opts, func(ids []string, _ QueryMeta) error {
+				got = append(got, ids...)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("QueryIDs returned error: %v", err)
+			}
+			if fetched != tt.fetched || len(got) != tt.fetched {
+				t.Errorf("QueryIDs fetched %d (%d passed to fn), want %d", fetched, len(got), tt.fetched)
+			}
+			if !reflect.DeepEqual(server.requests, tt.requests) {
+				t.Errorf("requests = %v, want %v", server.requests, tt.requests)
+			}
+			if meta.Pagination.Total != tt.total {
+				t.Errorf("meta total = %d, want %d", meta.Pagination.Total, tt.total)
+			}
+			if tt.fetched > 0 && got[0] != fmt.Sprintf("id-%d", tt.opts.Offset) {
+				t.Errorf("first ID = %s, want id-%d", got[0], tt.opts.Offset)
+			}
+		})
+	}
+}
+
+func TestQueryIDsOffsetLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		opts     PageOptions
+		requests int // Requests made before the error
+	}{
+		{name: "walk past the limit", total: 12000, opts: PageOptions{Limit: 500, All: true}, requests: 1},
+		{name: "max results past the limit", total: 12000, opts: PageOptions{Limit: 500, MaxResults: 10500}, requests: 1},
+		{name: "offset past the limit", total: 12000, opts: PageOptions{Offset: 10000}, requests: 0},
+		{name: "page past the limit", total: 12000, opts: PageOptions{Offset: 9950, Limit: 100}, requests: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &offsetServer{total: tt.total}
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+
+			called := false
+			_, _, err := newTestClient(srv).QueryIDs("/test/queries/v1", nil, tt.opts, func([]string, QueryMeta) error {
+				called = true
+				return nil
+			})
+			if err == nil || !strings.Contains(err.Error(), "only returns the first 10000") {
+				t.Fatalf("QueryIDs error = %v, want an offset limit error", err)
+			}
+			if called {
+				t.Error("QueryIDs passed IDs to fn before failing")
+			}
+			if len(server.requests) != tt.requests {
+				t.Errorf("made %d requests, want %d", len(server.requests), tt.requests)
+			}
+		})
+	}
+
+	// Walks that end within the limit are allowed
+	server := &offsetServer{total: 12000}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	fetched, _, err := newTestClient(srv).QueryIDs("/test/queries/v1", nil, PageOptions{Limit: 5000, MaxResults: 10000}, func([]string, QueryMeta) error { return nil })
+	if err != nil || fetched != 10000 {
+		t.Errorf("QueryIDs up to the limit = %d, %v; want 10000, nil", fetched, err)
+	}
+}
+
+func TestQueryIDsScroll(t *testing.T) {
+	const total = 25000
+	ids := makeIDs(total)
+	var requests []string
+
+	// The scroll endpoint returns the position of the next page as an opaque token
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/devices/queries/devices-scroll/v1" {
+			http.Error(w, "unexpected path "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+		token := r.URL.Query().Get("offset")
+		requests = append(requests, token)
+		start := 0
+		if token != "" {
+			start, _ = strconv.Atoi(strings.TrimPrefix(token, "token-"))
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(start+limit, total)
+
+		next := ""
+		if end < total {
+			next = fmt.Sprintf("token-%d", end)
+		}
+		fmt.Fprintf(w, `{"resources":%s,"meta":{"pagination":{"offset":%q,"total":%d,"expires_at":1}}}`, mustJSON(ids[start:end]), next, total)
+	}))
+	defer srv.Close()
+
+	var got []string
+	var offsets []int
+	fetched, meta, err := newTestClient(srv).QueryIDs("/devices/queries/devices/v1", nil, PageOptions{Limit: 5000, All: true}, func(page []string, meta QueryMeta) error {
+		got = append(got, page...)
+		offsets = append(offsets, meta.Pagination.Offset)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("QueryIDs returned error: %v", err)
+	}
+	if fetched != total || !reflect.DeepEqual(got, ids) {
+		t.Errorf("QueryIDs fetched %d IDs, want all %d in order", fetched, total)
+	}
+	if want := []string{"", "token-5000", "token-10000", "token-15000", "token-20000"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("scroll tokens = %v, want %v", requests, want)
+	}
+	if want := []int{0, 5000, 10000, 15000, 20000}; !reflect.DeepEqual(offsets, want) {
+		t.Errorf("page offsets = %v, want %v", offsets, want)
+	}
+	if meta.Pagination.Total != total {
+		t.Errorf("meta total = %d, want %d", meta.Pagination.Total, total)
+	}
+
+	// Max results stops the scroll early, shrinking the last page
+	requests = nil
+	fetched, _, err = newTestClient(srv).QueryIDs("/devices/queries/devices/v1", nil, PageOptions{Limit: 5000, MaxResults: 12000}, func([]string, QueryMeta) error { return nil })
+	if err != nil || fetched != 12000 || len(requests) != 3 {
+		t.Errorf("QueryIDs with max results = %d in %d requests, %v; want 12000 in 3", fetched, len(requests), err)
+	}
+}
+
+func mustJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func TestPageLimit(t *testing.T) {
+	tests := []struct {
+		pageSize, maxResults, fetched, want int
+	}{
+		{pageSize: 100, maxResults: 0, fetched: 500, want: 100},
+		{pageSize: 100, maxResults: 250, fetched: 200, want: 50},
+		{pageSize: 100, maxResults: 250, fetched: 100, want: 100},
+		{pageSize: 100, maxResults: 30, fetched: 0, want: 30},
+	}
+
+	for _, tt := range tests {
+		if got := pageLimit(tt.pageSize, tt.maxResults, tt.fetched); got != tt.want {
+			t.Errorf("pageLimit(%d, %d, %d) = %d, want %d", tt.pageSize, tt.maxResults, tt.fetched, got, tt.want)
+		}
+	}
+}
+
+func TestPageOptionsValidate(t *testing.T) {
+	tests := []struct {
+		opts PageOptions
+		err  string
+	}{
+		{opts: PageOptions{Limit: 10, Offset: 5, MaxResults: 20}},
+		{opts: PageOptions{Limit: -1}, err: "--limit must not be negative"},
+		{opts: PageOptions{Offset: -1}, err: "--offset must not be negative"},
+		{opts: PageOptions{MaxResults: -1}, err: "--max-results must not be negative"},
+	}
+
+	for _, tt := range tests {
+		err := tt.opts.Validate()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("%+v.Validate() = %v, want %q", tt.opts, err, tt.err)
+		}
+	}
+}
+
+func TestChunkIDs(t *testing.T) {
+	tests := []struct {
+		n, size int
+		want    []int // Size of each chunk
+	}{
+		{n: 0, size: 3, want: nil},
+		{n: 2, size: 3, want: []int{2}},
+		{n: 3, size: 3, want: []int{3}},
+		{n: 7, size: 3, want: []int{3, 3, 1}},
+		{n: 1000, size: 500, want: []int{500, 500}},
+	}
+
+	for _, tt := range tests {
+		ids := makeIDs(tt.n)
+		chunks := ChunkIDs(ids, tt.size)
+
+		var sizes []int
+		var joined []string
+		for _, c := range chunks {
+			sizes = append(sizes, len(c))
+			joined = append(joined, c...)
+		}
+		if !reflect.DeepEqual(sizes, tt.want) {
+			t.Errorf("ChunkIDs(%d IDs, %d) sizes = %v, want %v", tt.n, tt.size, sizes, tt.want)
+		}
+		if tt.n > 0 && !reflect.DeepEqual(joined, ids) {
+			t.Errorf("ChunkIDs(%d IDs, %d) does not keep every ID in order", tt.n, tt.size)
+		}
+	}
+
+	// Appending to a chunk must not overwrite the next one
+	ids := makeIDs(4)
+	chunks := ChunkIDs(ids, 2)
+	_ = append(chunks[0], "extra")
+	if chunks[1][0] != "id-2" {
+		t.Errorf("appending to a chunk overwrote the next chunk: %v", chunks[1])
+	}
+}
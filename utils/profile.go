@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultProfile is the name of the profile stored under the legacy falcon key
+const DefaultProfile = "default"
+
+// ProfileName is the profile selected with --profile. When empty, the
+// FALCON_PROFILE environment variable and then the active_profile setting are used.
+var ProfileName string
+
+// Verbose is set by --verbose to log retries and other diagnostics
+var Verbose bool
+
+// profileNamePattern restricts profile names to characters that are safe in
+// config keys; viper lowercases keys, so names are lowercase too
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Profile holds the credentials for one Falcon tenant
+type Profile struct {
+	Name          string `json:"name" mapstructure:"-"`
+	ClientID      string `json:"client_id" mapstructure:"client_id"`
+	ClientSecret  string `json:"-" mapstructure:"client_secret"` // Only set for the plaintext backend
+	CloudRegion   string `json:"cloud_region" mapstructure:"cloud_region"`
+	SecretBackend string `json:"secret_backend" mapstructure:"client_secret_backend"`
+}
+
+// Secret returns the profile's client secret, reading it from the profile's
+// secret backend if it is not stored in the config file
+func (p *Profile) Secret() (string, error) {
+	if p.SecretBackend == "" || p.SecretBackend == SecretBackendPlaintext {
+		return p.ClientSecret, nil
+	}
+
+	store, err := NewSecretStore(p.SecretBackend)
+	if err != nil {
+		return "", err
+	}
+	return store.Get(p.Name)
+}
+
+// ValidateProfileName checks that a profile name can be stored in the config
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s': use lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ActiveProfileName returns the name of the profile to use, from --profile,
+// FALCON_PROFILE or the active_profile setting, in that order
+func ActiveProfileName() string {
+	if ProfileName != "" {
+		return ProfileName
+	}
+	if name := os.Getenv("FALCON_PROFILE"); name != "" {
+		return name
+	}
+	if name := viper.GetString("active_profile"); name != "" {
+		return name
+	}
+	return DefaultProfile
+}
+
+// ProfileKey returns the config key holding the named profile
+func ProfileKey(name string) string {
+	if name == DefaultProfile {
+		return "falcon"
+	}
+	return "profiles." + name
+}
+
+// GetProfile returns the named profile from the config. The default profile is
+// read from the falcon key so configs written before profiles existed keep working.
+func GetProfile(name string) (*Profile, error) {
+	key := ProfileKey(name)
+	if name != DefaultProfile && !viper.IsSet(key) {
+		return nil, fmt.Errorf("profile '%s' not found. Please run 'falcon-cli init --profile %s' first", name, name)
+	}
+
+	profile := &Profile{
+		Name:          name,
+		ClientID:      viper.GetString(key + ".client_id"),
+		ClientSecret:  viper.GetString(key + ".client_secret"),
+		CloudRegion:   viper.GetString(key + ".cloud_region"),
+		SecretBackend: viper.GetString(key + ".client_secret_backend"),
+	}
+	return profile, nil
+}
+
+// ActiveProfile returns the profile selected by ActiveProfileName
+func ActiveProfile() (*Profile, error) {
+	return GetProfile(ActiveProfileName())
+}
+
+// ProfileNames returns the names of all configured profiles
+func ProfileNames() []string {
+	var names []string
+	if viper.GetString("falcon.client_id") != "" {
+		names = append(names, DefaultProfile)
+	}
+	for name := range viper.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = time.Second
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	MaxRetries int           // Number of retries after the first attempt
+	BaseDelay  time.Duration // Delay before the first retry, doubled for each further retry
+	MaxDelay   time.Duration // Upper bound for a single delay
+}
+
+// retryPolicyFromConfig reads the retry policy from the falcon.max_retries,
+// falcon.retry_base_delay and falcon.retry_max_delay settings
+func retryPolicyFromConfig() RetryPolicy {
+	policy := RetryPolicy{
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultRetryBaseDelay,
+		MaxDelay:   defaultRetryMaxDelay,
+	}
+	if viper.IsSet("falcon.max_retries") {
+		policy.MaxRetries = viper.GetInt("falcon.max_retries")
+	}
+	if viper.IsSet("falcon.retry_base_delay") {
+		policy.BaseDelay = viper.GetDuration("falcon.retry_base_delay")
+	}
+	if viper.IsSet("falcon.retry_max_delay") {
+		policy.MaxDelay = viper.GetDuration("falcon.retry_max_delay")
+	}
+	return policy
+}
+
+// backoff returns the delay before the given retry (starting at 1), using
+// exponential backoff with jitter
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Pick a delay between half and the full backoff so concurrent clients spread out
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// isRetryableStatus reports whether a response status is worth retrying
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rateLimitReset returns when the rate limit window resets according to the
+// X-RateLimit-RetryAfter (epoch seconds) or Retry-After (seconds) headers, or
+// the zero time if neither is present
+func rateLimitReset(header http.Header) time.Time {
+	if value := header.Get("X-RateLimit-RetryAfter"); value != "" {
+		if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.Unix(epoch, 0)
+		}
+	}
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Now().Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	return time.Time{}
+}
+
+// logf writes a diagnostic line to stderr when --verbose is set
+func logf(format string, args ...interface{}) {
+	if Verbose {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
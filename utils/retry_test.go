@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// statusServer answers each request with the next status in statuses, repeating
+// the last one, and counts the requests
+type statusServer struct {
+	statuses []int
+	header   http.Header
+	attempts int
+}
+
+func (s *statusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := s.statuses[min(s.attempts, len(s.statuses)-1)]
+	s.attempts++
+	for key, values := range s.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(status)
+	if status == http.StatusOK {
+		w.Write([]byte(`{"resources":[]}`))
+	} else {
+		w.Write([]byte(`{"errors":[{"code":` + strconv.Itoa(status) + `,"message":"try again"}]}`))
+	}
+}
+
+// newRetryClient returns a test client that retries quickly
+func newRetryClient(srv *httptest.Server, maxRetries int) *FalconClient {
+	fc := newTestClient(srv)
+	fc.Retry = RetryPolicy{MaxRetries: maxRetries, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	return fc
+}
+
+func TestRetry(t *testing.T) {
+	requests := map[string]func(fc *FalconClient) (*http.Response, error){
+		"GET": func(fc *FalconClient) (*http.Response, error) { return fc.Get("/test", nil) },
+		"PostWithRetry": func(fc *FalconClient) (*http.Response, error) {
+			return fc.PostWithRetry("/test", strings.NewReader(`{}`))
+		},
+		"Post":  func(fc *FalconClient) (*http.Response, error) { return fc.Post("/test", strings.NewReader(`{}`)) },
+		"Patch": func(fc *FalconClient) (*http.Response, error) { return fc.Patch("/test", strings.NewReader(`{}`)) },
+	}
+
+	tests := []struct {
+		name         string
+		request      string
+		statuses     []int
+		maxRetries   int
+		wantAttempts int
+		wantStatus   int // status of the returned APIError, or 0 for success
+	}{
+		{name: "GET succeeds after 429 and 503", request: "GET", statuses: []int{429, 503, 200}, maxRetries: 3, wantAttempts: 3},
+		{name: "PostWithRetry succeeds after 429 and 503", request: "PostWithRetry", statuses: []int{429, 503, 200}, maxRetries: 3, wantAttempts: 3},
+		{name: "GET gives up after max retries", request: "GET", statuses: []int{503}, maxRetries: 2, wantAttempts: 3, wantStatus: 503},
+		{name: "GET without retries", request: "GET", statuses: []int{429, 200}, maxRetries: 0, wantAttempts: 1, wantStatus: 429},
+		{name: "GET does not retry 400", request: "GET", statuses: []int{400, 200}, maxRetries: 3, wantAttempts: 1, wantStatus: 400},
+		{name: "Post is not retried on 429", request: "Post", statuses: []int{429, 200}, maxRetries: 3, wantAttempts: 1, wantStatus: 429},
+		{name: "Post is not retried on 503", request: "Post", statuses: []int{503, 200}, maxRetries: 3, wantAttempts: 1, wantStatus: 503},
+		{name: "Patch is not retried on 503", request: "Patch", statuses: []int{503, 200}, maxRetries: 3, wantAttempts: 1, wantStatus: 503},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &statusServer{statuses: tt.statuses}
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+
+			resp, err := requests[tt.request](newRetryClient(srv, tt.maxRetries))
+			if server.attempts != tt.wantAttempts {
+				t.Errorf("made %d attempts, want %d", server.attempts, tt.wantAttempts)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("request returned error: %v", err)
+				}
+				resp.Body.Close()
+				return
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("error = %v, want an APIError with status %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRetryWaitsForRateLimitReset(t *testing.T) {
+	// The reset is given in whole epoch seconds, so two seconds ahead is at least one second away
+	reset := time.Now().Add(2 * time.Second).Unix()
+	server := &statusServer{
+		statuses: []int{429, 200},
+		header:   http.Header{"X-Ratelimit-Retryafter": {strconv.FormatInt(reset, 10)}},
+	}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	start := time.Now()
+	resp, err := newRetryClient(srv, 3).Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want the X-RateLimit-RetryAfter reset to be waited for", elapsed)
+	}
+	if server.attempts != 2 {
+		t.Errorf("made %d attempts, want 2", server.attempts)
+	}
+}
+
+func TestRateLimitRemainingRecordsReset(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Unix()
+	server := &statusServer{
+		statuses: []int{200},
+		header: http.Header{
+			"X-Ratelimit-Remaining":  {"0"},
+			"X-Ratelimit-Retryafter": {strconv.FormatInt(reset, 10)},
+		},
+	}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	fc := newRetryClient(srv, 0)
+	resp, err := fc.Get("/test", nil)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if !fc.rateLimitedUntil.Equal(time.Unix(reset, 0)) {
+		t.Errorf("rateLimitedUntil = %s, want %s", fc.rateLimitedUntil, time.Unix(reset, 0))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		retry int
+		full  time.Duration // backoff before jitter
+	}{
+		{retry: 1, full: 100 * time.Millisecond},
+		{retry: 2, full: 200 * time.Millisecond},
+		{retry: 3, full: 400 * time.Millisecond},
+		{retry: 4, full: 800 * time.Millisecond},
+		{retry: 5, full: time.Second},
+		{retry: 50, full: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.retry), func(t *testing.T) {
+			// Jitter picks between half and the full backoff, never above MaxDelay
+			for i := 0; i < 100; i++ {
+				delay := policy.backoff(tt.retry)
+				if delay < tt.full/2 || delay > tt.full {
+					t.Fatalf("backoff(%d) = %s, want between %s and %s", tt.retry, delay, tt.full/2, tt.full)
+				}
+			}
+		})
+	}
+
+	if delay := (RetryPolicy{}).backoff(1); delay != 0 {
+		t.Errorf("backoff without a base delay = %s, want 0", delay)
+	}
+}
+
+func TestRateLimitReset(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration // from now, with a second of tolerance
+		zero   bool
+	}{
+		{name: "X-RateLimit-RetryAfter", header: http.Header{"X-Ratelimit-Retryafter": {strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10)}}, want: 30 * time.Second},
+		{name: "Retry-After", header: http.Header{"Retry-After": {"10"}}, want: 10 * time.Second},
+		{name: "X-RateLimit-RetryAfter wins", header: http.Header{
+			"X-Ratelimit-Retryafter": {strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10)},
+			"Retry-After":            {"10"},
+		}, want: 30 * time.Second},
+		{name: "invalid value", header: http.Header{"Retry-After": {"soon"}}, zero: true},
+		{name: "no header", header: http.Header{}, zero: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rateLimitReset(tt.header)
+			if tt.zero {
+				if !got.IsZero() {
+					t.Errorf("rateLimitReset = %s, want the zero time", got)
+				}
+				return
+			}
+			if wait := time.Until(got); wait < tt.want-time.Second || wait > tt.want+time.Second {
+				t.Errorf("rateLimitReset is %s away, want about %s", wait, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyFromConfig(t *testing.T) {
+	defer viper.Reset()
+
+	if got := retryPolicyFromConfig(); got != (RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second}) {
+		t.Errorf("default policy = %+v", got)
+	}
+
+	viper.Set("falcon.max_retries", 0)
+	viper.Set("falcon.retry_base_delay", "250ms")
+	viper.Set("falcon.retry_max_delay", "2s")
+	if got := retryPolicyFromConfig(); got != (RetryPolicy{MaxRetries: 0, BaseDelay: 250 * time.Millisecond, MaxDelay: 2 * time.Second}) {
+		t.Errorf("configured policy = %+v", got)
+	}
+}
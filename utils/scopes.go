@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ScopeCheck reports whether an API client was granted read access to a scope
+type ScopeCheck struct {
+	Scope   string `json:"scope"`
+	Granted bool   `json:"granted"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// scopeProbes are read-only endpoints used to check each scope
+var scopeProbes = []struct {
+	scope    string
+	endpoint string
+}{
+	{"Hosts: Read", "/devices/queries/devices/v1"},
+	{"Host groups: Read", "/devices/queries/host-groups/v1"},
+	{"Alerts: Read", "/alerts/queries/alerts/v2"},
+	{"Incidents: Read", "/incidents/queries/incidents/v1"},
+	{"Detections: Read", "/detects/queries/detects/v1"},
+}
+
+// CheckScopes reports which read scopes an API client was granted, starting with
+// a token already requested for it. The Falcon API has no endpoint that lists an
+// API client's scopes, so each scope is probed with a minimal query; a 403 means
+// the scope is missing. The probes use their own token manager, so they never
+// touch the active profile or the token cache.
+func CheckScopes(clientID, clientSecret, cloudRegion string, token *TokenResponse) ([]ScopeCheck, error) {
+	baseURL, ok := RegionBaseURL[cloudRegion]
+	if !ok {
+		return nil, fmt.Errorf("invalid cloud region: %s", cloudRegion)
+	}
+
+	client := &FalconClient{
+		BaseURL: baseURL,
+		Tokens:  NewStandaloneTokenManager(clientID, clientSecret, cloudRegion, token),
+		Client:  &http.Client{Timeout: 30 * time.Second},
+		Retry:   retryPolicyFromConfig(),
+	}
+
+	checks := make([]ScopeCheck, 0, len(scopeProbes))
+	for _, probe := range scopeProbes {
+		check := ScopeCheck{Scope: probe.scope}
+
+		resp, err := client.Get(probe.endpoint, map[string]string{"limit": "1"})
+		var apiErr *APIError
+		switch {
+		case err == nil:
+			resp.Body.Close()
+			check.Granted = true
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden:
+			check.Detail = "missing"
+		default:
+			check.Detail = err.Error()
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckScopesUsesOwnCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// A token cached for another profile must survive the probes
+	if err := saveCachedToken("active-id", "test", "active-token", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("saveCachedToken returned error: %v", err)
+	}
+
+	var tokenRequests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == tokenEndpoint:
+			r.ParseForm()
+			tokenRequests = append(tokenRequests, r.PostForm.Get("client_id")+":"+r.PostForm.Get("client_secret"))
+			json.NewEncoder(w).Encode(TokenResponse{AccessToken: "fresh-token", ExpiresIn: 1800})
+		case r.Header.Get("Authorization") != "Bearer fresh-token":
+			// The first token is rejected, so the probe has to refresh it
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/detects/queries/detects/v1":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.Write([]byte(`{"resources":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	RegionBaseURL["test"] = srv.URL
+	defer delete(RegionBaseURL, "test")
+
+	checks, err := CheckScopes("new-id", "new-secret", "test", &TokenResponse{AccessToken: "stale-token", ExpiresIn: 1800})
+	if err != nil {
+		t.Fatalf("CheckScopes returned error: %v", err)
+	}
+
+	if len(tokenRequests) != 1 || tokenRequests[0] != "new-id:new-secret" {
+		t.Errorf("token requests = %v, want one with the checked credentials", tokenRequests)
+	}
+	for _, check := range checks {
+		want := check.Scope != "Detections: Read"
+		if check.Granted != want {
+			t.Errorf("scope %s granted = %v, want %v", check.Scope, check.Granted, want)
+		}
+	}
+
+	// Only the active profile's cached token exists; nothing was written for the probe
+	cached, err := loadCachedToken("active-id", "test")
+	if err != nil || cached == nil || cached.AccessToken != "active-token" {
+		t.Errorf("cached token of the active profile = %v, %v; want it kept", cached, err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(home, ".falcon-cli", "tokens"))
+	if len(entries) != 1 {
+		t.Errorf("token cache has %d files, want 1", len(entries))
+	}
+}
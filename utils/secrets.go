@@ -0,0 +1,294 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/zalando/go-keyring"
+)
+
+// Secret backends a profile's client secret can be stored in
+const (
+	SecretBackendPlaintext = "plaintext" // In the config file, as before backends existed
+	SecretBackendKeyring   = "keyring"   // In the OS keyring (Secret Service, macOS Keychain, Windows Credential Manager)
+	SecretBackendFile      = "file"      // In a passphrase-encrypted file under ~/.falcon-cli
+)
+
+// SecretBackends lists the available secret backends
+var SecretBackends = []string{SecretBackendKeyring, SecretBackendFile, SecretBackendPlaintext}
+
+const (
+	keyringService     = "falcon-cli"
+	secretsFileName    = "secrets.enc"
+	passphraseEnv      = "FALCON_CLI_PASSPHRASE"
+	pbkdf2Iterations   = 600000
+	secretsFileVersion = 1
+)
+
+// SecretStore stores client secrets outside the config file, keyed by profile name
+type SecretStore interface {
+	Name() string
+	Get(profile string) (string, error)
+	Set(profile, secret string) error
+	Delete(profile string) error
+}
+
+// NewSecretStore returns the store for a backend. The plaintext backend has no store.
+func NewSecretStore(backend string) (SecretStore, error) {
+	switch backend {
+	case SecretBackendKeyring:
+		return keyringStore{}, nil
+	case SecretBackendFile:
+		path, err := secretsFilePath()
+		if err != nil {
+			return nil, err
+		}
+		return &fileStore{path: path}, nil
+	}
+	return nil, fmt.Errorf("unknown secret backend '%s'", backend)
+}
+
+// KeyringAvailable reports whether the OS keyring can be used, which is not the
+// case in most containers and headless sessions
+func KeyringAvailable() bool {
+	_, err := keyring.Get(keyringService, "availability-check")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+// keyringStore keeps secrets in the OS keyring
+type keyringStore struct{}
+
+func (keyringStore) Name() string {
+	return SecretBackendKeyring
+}
+
+func (keyringStore) Get(profile string) (string, error) {
+	secret, err := keyring.Get(keyringService, profile)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("no client secret for profile '%s' in the OS keyring", profile)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading OS keyring: %v", err)
+	}
+	return secret, nil
+}
+
+func (keyringStore) Set(profile, secret string) error {
+	if err := keyring.Set(keyringService, profile, secret); err != nil {
+		return fmt.Errorf("error writing OS keyring: %v", err)
+	}
+	return nil
+}
+
+func (keyringStore) Delete(profile string) error {
+	if err := keyring.Delete(keyringService, profile); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("error deleting from OS keyring: %v", err)
+	}
+	return nil
+}
+
+// encryptedSecrets is the on-disk form of the encrypted secrets file
+type encryptedSecrets struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// fileStore keeps secrets in a file encrypted with AES-256-GCM, using a key
+// derived from a passphrase with PBKDF2. The passphrase is read from
+// FALCON_CLI_PASSPHRASE or prompted for.
+type fileStore struct {
+	path       string
+	passphrase string
+}
+
+func (s *fileStore) Name() string {
+	return SecretBackendFile
+}
+
+func (s *fileStore) Get(profile string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[profile]
+	if !ok {
+		return "", fmt.Errorf("no client secret for profile '%s' in %s", profile, s.path)
+	}
+	return secret, nil
+}
+
+func (s *fileStore) Set(profile, secret string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[profile] = secret
+	return s.save(secrets)
+}
+
+func (s *fileStore) Delete(profile string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[profile]; !ok {
+		return nil
+	}
+	delete(secrets, profile)
+	return s.save(secrets)
+}
+
+// getPassphrase returns the passphrase, prompting for it once if needed
+func (s *fileStore) getPassphrase(confirm bool) (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		s.passphrase = passphrase
+		return passphrase, nil
+	}
+
+	var passphrase string
+	prompt := &survey.Password{Message: "Enter the passphrase for the Falcon CLI secrets file:"}
+	if err := survey.AskOne(prompt, &passphrase, survey.WithValidator(survey.Required)); err != nil {
+		return "", fmt.Errorf("error reading passphrase (set %s to avoid the prompt): %v", passphraseEnv, err)
+	}
+
+	if confirm {
+		var again string
+		prompt := &survey.Password{Message: "Confirm the passphrase:"}
+		if err := survey.AskOne(prompt, &again); err != nil {
+			return "", fmt.Errorf("error reading passphrase: %v", err)
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	s.passphrase = passphrase
+	return passphrase, nil
+}
+
+// load decrypts the secrets file. A missing file holds no secrets.
+func (s *fileStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading secrets file: %v", err)
+	}
+
+	var file encryptedSecrets
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing secrets file: %v", err)
+	}
+	if file.Version != secretsFileVersion {
+		return nil, fmt.Errorf("unsupported secrets file version %d", file.Version)
+	}
+
+	passphrase, err := s.getPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newSecretsCipher(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	// Open panics on a nonce of the wrong size, which a damaged file can have
+	if len(file.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("error decrypting secrets file: corrupted file")
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting secrets file: wrong passphrase or corrupted file")
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("error parsing secrets file: %v", err)
+	}
+	return secrets, nil
+}
+
+// save encrypts the secrets with a fresh salt and nonce and writes them with owner-only permissions
+func (s *fileStore) save(secrets map[string]string) error {
+	_, statErr := os.Stat(s.path)
+	passphrase, err := s.getPassphrase(os.IsNotExist(statErr))
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("error encoding secrets: %v", err)
+	}
+
+	file := encryptedSecrets{
+		Version: secretsFileVersion,
+		Salt:    make([]byte, 16),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return fmt.Errorf("error generating salt: %v", err)
+	}
+
+	gcm, err := newSecretsCipher(passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("error generating nonce: %v", err)
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("error encoding secrets file: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("error creating secrets directory: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("error writing secrets file: %v", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(s.path, 0600)
+}
+
+// newSecretsCipher derives the AES-256-GCM cipher for a passphrase and salt
+func newSecretsCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %v", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretsFilePath returns the location of the encrypted secrets file
+func secretsFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home directory: %v", err)
+	}
+	return filepath.Join(home, ".falcon-cli", secretsFileName), nil
+}
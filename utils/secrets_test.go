@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestFileStore returns a file store in a temporary directory
+func newTestFileStore(t *testing.T, passphrase string) *fileStore {
+	t.Helper()
+	return &fileStore{path: filepath.Join(t.TempDir(), secretsFileName), passphrase: passphrase}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	store := newTestFileStore(t, "correct horse")
+	if err := store.Set("default", "secret-1"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if err := store.Set("prod", "secret-2"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+
+	// A new store reads the file with only the passphrase
+	reopened := &fileStore{path: store.path, passphrase: "correct horse"}
+	for profile, want := range map[string]string{"default": "secret-1", "prod": "secret-2"} {
+		got, err := reopened.Get(profile)
+		if err != nil {
+			t.Fatalf("Get(%q) returned error: %v", profile, err)
+		}
+		if got != want {
+			t.Errorf("Get(%q) = %q, want %q", profile, got, want)
+		}
+	}
+
+	if err := reopened.Delete("default"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := reopened.Get("default"); err == nil {
+		t.Error("Get of a deleted profile returned no error")
+	}
+	if got, err := reopened.Get("prod"); err != nil || got != "secret-2" {
+		t.Errorf("Get(prod) after deleting another profile = %q, %v", got, err)
+	}
+
+	info, err := os.Stat(store.path)
+	if err != nil {
+		t.Fatalf("Stat returned error: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("secrets file mode = %o, want 600", mode)
+	}
+
+	// The secrets are not stored in the clear
+	data, _ := os.ReadFile(store.path)
+	if strings.Contains(string(data), "secret-2") {
+		t.Error("secrets file contains a secret in plaintext")
+	}
+}
+
+func TestFileStoreWrongPassphrase(t *testing.T) {
+	store := newTestFileStore(t, "correct horse")
+	if err := store.Set("default", "secret-1"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	before, _ := os.ReadFile(store.path)
+
+	wrong := &fileStore{path: store.path, passphrase: "battery staple"}
+	secret, err := wrong.Get("default")
+	if err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Fatalf("Get with the wrong passphrase = %q, %v; want a wrong passphrase error", secret, err)
+	}
+	if secret != "" {
+		t.Errorf("Get with the wrong passphrase returned %q", secret)
+	}
+
+	// Writing with the wrong passphrase must not replace the existing secrets
+	if err := wrong.Set("other", "secret-2"); err == nil {
+		t.Error("Set with the wrong passphrase returned no error")
+	}
+	after, _ := os.ReadFile(store.path)
+	if string(before) != string(after) {
+		t.Error("Set with the wrong passphrase changed the secrets file")
+	}
+}
+
+func TestFileStoreCorruptedFile(t *testing.T) {
+	store := newTestFileStore(t, "correct horse")
+	if err := store.Set("default", "secret-1"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		t.Fatalf("ReadFile returned error: %v", err)
+	}
+	var valid encryptedSecrets
+	if err := json.Unmarshal(data, &valid); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+
+	// encode writes a modified copy of the valid file
+	encode := func(modify func(f *encryptedSecrets)) []byte {
+		f := valid
+		f.Salt = append([]byte(nil), valid.Salt...)
+		f.Nonce = append([]byte(nil), valid.Nonce...)
+		f.Ciphertext = append([]byte(nil), valid.Ciphertext...)
+		modify(&f)
+		out, _ := json.Marshal(f)
+		return out
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{name: "empty file", data: nil, err: "error parsing secrets file"},
+		{name: "truncated file", data: data[:len(data)/2], err: "error parsing secrets file"},
+		{name: "unknown version", data: encode(func(f *encryptedSecrets) { f.Version = 2 }), err: "unsupported secrets file version 2"},
+		{name: "flipped ciphertext bit", data: encode(func(f *encryptedSecrets) { f.Ciphertext[0] ^= 1 }), err: "corrupted file"},
+		{name: "truncated ciphertext", data: encode(func(f *encryptedSecrets) { f.Ciphertext = f.Ciphertext[:len(f.Ciphertext)-4] }), err: "corrupted file"},
+		{name: "changed salt", data: encode(func(f *encryptedSecrets) { f.Salt[0] ^= 1 }), err: "corrupted file"},
+		{name: "truncated nonce", data: encode(func(f *encryptedSecrets) { f.Nonce = f.Nonce[:4] }), err: "corrupted file"},
+		{name: "missing nonce", data: encode(func(f *encryptedSecrets) { f.Nonce = nil }), err: "corrupted file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(store.path, tt.data, 0600); err != nil {
+				t.Fatalf("WriteFile returned error: %v", err)
+			}
+
+			reopened := &fileStore{path: store.path, passphrase: "correct horse"}
+			secret, err := reopened.Get("default")
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("Get = %q, %v; want an error containing %q", secret, err, tt.err)
+			}
+
+			// A damaged file is left alone rather than overwritten with a new secret
+			if err := reopened.Set("prod", "secret-2"); err == nil {
+				t.Error("Set on a damaged file returned no error")
+			}
+			after, _ := os.ReadFile(store.path)
+			if string(after) != string(tt.data) {
+				t.Error("Set on a damaged file changed it")
+			}
+		})
+	}
+}
+
+func TestFileStoreMissingFile(t *testing.T) {
+	store := newTestFileStore(t, "correct horse")
+	if _, err := store.Get("default"); err == nil || !strings.Contains(err.Error(), "no client secret for profile 'default'") {
+		t.Errorf("Get without a secrets file = %v, want a missing secret error", err)
+	}
+	if err := store.Delete("default"); err != nil {
+		t.Errorf("Delete without a secrets file returned error: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cachedToken is the on-disk form of a token shared across invocations
+type cachedToken struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ClientID    string    `json:"client_id"`
+	CloudRegion string    `json:"cloud_region"`
+}
+
+// tokenCachePath returns the cache file for a client ID and region. The key is
+// hashed so client IDs do not end up in file names.
+func tokenCachePath(clientID, cloudRegion string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting home directory: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(clientID + "@" + cloudRegion))
+	name := hex.EncodeToString(sum[:])[:32] + ".json"
+	return filepath.Join(home, ".falcon-cli", "tokens", name), nil
+}
+
+// loadCachedToken returns the cached token for a client ID and region, or nil
+// if there is no cached token or it has expired
+func loadCachedToken(clientID, cloudRegion string) (*cachedToken, error) {
+	if clientID == "" {
+		return nil, nil
+	}
+
+	path, err := tokenCachePath(clientID, cloudRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading token cache: %v", err)
+	}
+
+	var cached cachedToken
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("error parsing token cache: %v", err)
+	}
+
+	// Guard against hash collisions and stale files
+	if cached.ClientID != clientID || cached.CloudRegion != cloudRegion || !time.Now().Before(cached.ExpiresAt) {
+		return nil, nil
+	}
+	return &cached, nil
+}
+
+// saveCachedToken writes a token to the cache with owner-only permissions
+func saveCachedToken(clientID, cloudRegion, token string, expiresAt time.Time) error {
+	path, err := tokenCachePath(clientID, cloudRegion)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating token cache directory: %v", err)
+	}
+
+	data, err := json.Marshal(cachedToken{
+		AccessToken: token,
+		ExpiresAt:   expiresAt,
+		ClientID:    clientID,
+		CloudRegion: cloudRegion,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding token cache: %v", err)
+	}
+
+	// Write to a temporary file and rename so concurrent invocations never read a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return fmt.Errorf("error writing token cache: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing token cache: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing token cache: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing token cache: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing token cache: %v", err)
+	}
+	return nil
+}
+
+// RemoveCachedToken deletes the cached token for a client ID and region. It is
+// not an error if there is none.
+func RemoveCachedToken(clientID, cloudRegion string) error {
+	path, err := tokenCachePath(clientID, cloudRegion)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing token cache: %v", err)
+	}
+	return nil
+}
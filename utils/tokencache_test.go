@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemoveCachedToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	expiresAt := time.Now().Add(time.Hour)
+	if err := saveCachedToken("id-1", "us-1", "token-1", expiresAt); err != nil {
+		t.Fatalf("saveCachedToken returned error: %v", err)
+	}
+	if err := saveCachedToken("id-2", "us-1", "token-2", expiresAt); err != nil {
+		t.Fatalf("saveCachedToken returned error: %v", err)
+	}
+
+	if err := RemoveCachedToken("id-1", "us-1"); err != nil {
+		t.Fatalf("RemoveCachedToken returned error: %v", err)
+	}
+	if cached, err := loadCachedToken("id-1", "us-1"); err != nil || cached != nil {
+		t.Errorf("loadCachedToken after removal = %v, %v, want no token", cached, err)
+	}
+	if cached, err := loadCachedToken("id-2", "us-1"); err != nil || cached == nil || cached.AccessToken != "token-2" {
+		t.Errorf("token of another client was not kept: %v, %v", cached, err)
+	}
+
+	// Removing a token that is not cached is not an error
+	if err := RemoveCachedToken("id-1", "us-1"); err != nil {
+		t.Errorf("RemoveCachedToken of a missing token returned error: %v", err)
+	}
+}